@@ -0,0 +1,68 @@
+// Package yamlpath addresses nodes of a yaml.Node tree by a path of mapping
+// keys (string) and sequence indexes (int).
+package yamlpath
+
+import (
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Line returns the line of the node at path or of its closest existing parent.
+func Line(root *yaml.Node, path []any) int {
+	if root == nil {
+		return 0
+	}
+	node := root
+	if node.Kind == yaml.DocumentNode && len(node.Content) > 0 {
+		node = node.Content[0]
+	}
+	line := node.Line
+	for _, elem := range path {
+		next := Child(node, elem)
+		if next == nil {
+			break
+		}
+		node = next
+		line = node.Line
+	}
+	return line
+}
+
+// Child returns the value of a mapping key or the item of a sequence index.
+func Child(node *yaml.Node, elem any) *yaml.Node {
+	switch e := elem.(type) {
+	case string:
+		if node.Kind != yaml.MappingNode {
+			return nil
+		}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if node.Content[i].Value == e {
+				return node.Content[i+1]
+			}
+		}
+	case int:
+		if node.Kind == yaml.SequenceNode && e < len(node.Content) {
+			return node.Content[e]
+		}
+	}
+	return nil
+}
+
+// Format renders path like "updates[0].schedule.day".
+func Format(path []any) string {
+	var b strings.Builder
+	for _, elem := range path {
+		switch e := elem.(type) {
+		case int:
+			fmt.Fprintf(&b, "[%d]", e)
+		default:
+			if b.Len() > 0 {
+				b.WriteString(".")
+			}
+			fmt.Fprint(&b, e)
+		}
+	}
+	return b.String()
+}
@@ -3,26 +3,9 @@ package main
 import (
 	"os"
 
-	"github.com/containifyci/dependabot-templater/pkg/dependabot"
+	"github.com/containifyci/dependabot-templater/pkg/cli"
 )
 
 func main() {
-	args := os.Args[1:]
-	kind := args[0]
-	path := args[1]
-	interval := arg(args, 2, "weekly")
-	day := arg(args, 3, "")
-	bot := dependabot.New(dependabot.WithKind(kind), dependabot.WithInterval(interval), dependabot.WithDay(day))
-	_, dependabot := bot.GenarateConfigFile(path)
-	_, err := os.Stdout.WriteString(dependabot)
-	if err != nil {
-		panic(err)
-	}
-}
-
-func arg(args []string, pos int, def string) string {
-	if len(args) > pos {
-		return args[pos]
-	}
-	return def
+	os.Exit(cli.Run(os.Args[1:], os.Stdout, os.Stderr))
 }
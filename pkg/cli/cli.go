@@ -0,0 +1,539 @@
+// Package cli implements the dependabot-templater command line.
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/containifyci/dependabot-templater/pkg/config"
+	"github.com/containifyci/dependabot-templater/pkg/dependabot"
+	"github.com/containifyci/dependabot-templater/pkg/settings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Exit codes of Run.
+const (
+	ExitOK = 0
+	// ExitError reports a failed run, like an unreadable file or a failed kind.
+	ExitError = 1
+	// ExitUsage reports invalid flags or arguments.
+	ExitUsage = 2
+	// ExitFailed reports drift found by check or problems found by validate.
+	ExitFailed = 3
+	// ExitChanged reports that generate updated the written file.
+	ExitChanged = 4
+)
+
+type command struct {
+	name    string
+	args    string
+	summary string
+	run     func(c *cli, args []string) int
+}
+
+var commands []command
+
+func init() {
+	commands = []command{
+		{"generate", "[kind] [path] [interval] [day]", "generate the dependabot.yml", (*cli).generate},
+		{"check", "[kind] [path]", "compare the committed dependabot.yml with the generated one", (*cli).check},
+		{"validate", "[file...]", "validate dependabot.yml files", (*cli).validate},
+		{"list-kinds", "", "list the supported kinds", (*cli).listKinds},
+		{"detect", "[kind] [path]", "list the folders and files found per kind", (*cli).detect},
+		{"explain", "<dir>", "explain why the kinds do or don't generate an entry for a directory", (*cli).explain},
+		{"completion", "bash|zsh|fish", "print the shell completion script", (*cli).completion},
+	}
+}
+
+type cli struct {
+	stdout io.Writer
+	stderr io.Writer
+}
+
+// Run executes the command line args without the program name and returns
+// the exit code. Arguments that don't start with a command are run by
+// generate, which keeps the positional form working.
+func Run(args []string, stdout, stderr io.Writer) int {
+	c := &cli{stdout: stdout, stderr: stderr}
+	if len(args) > 0 {
+		switch args[0] {
+		case "help", "-h", "-help", "--help":
+			c.usage(stdout)
+			return ExitOK
+		}
+		for _, cmd := range commands {
+			if cmd.name == args[0] {
+				return cmd.run(c, args[1:])
+			}
+		}
+	}
+	return c.generate(args)
+}
+
+func (c *cli) usage(w io.Writer) {
+	fmt.Fprintln(w, "usage: dependabot-templater <command> [flags] [args]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "commands:")
+	for _, cmd := range commands {
+		fmt.Fprintf(w, "  %-11s %s\n", cmd.name, cmd.summary)
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Without a command the arguments are passed to generate.")
+	fmt.Fprintln(w, "Run 'dependabot-templater <command> --help' for the flags of a command.")
+}
+
+// flagSet returns the flags of the named command, parse errors are reported
+// by the caller.
+func (c *cli) flagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(c.stderr)
+	fs.Usage = func() {
+		for _, cmd := range commands {
+			if cmd.name == name {
+				fmt.Fprintf(c.stderr, "usage: dependabot-templater %s [flags] %s\n\n%s\n", cmd.name, cmd.args, cmd.summary)
+			}
+		}
+		if hasFlags(fs) {
+			fmt.Fprintln(c.stderr, "\nflags:")
+			fs.PrintDefaults()
+		}
+	}
+	return fs
+}
+
+func hasFlags(fs *flag.FlagSet) bool {
+	found := false
+	fs.VisitAll(func(*flag.Flag) { found = true })
+	return found
+}
+
+// parse parses the flags, ok is false if the command has to return code.
+// Flags may follow the arguments, like in "all . --write", everything after
+// "--" is an argument.
+func parse(fs *flag.FlagSet, args []string) (code int, ok bool) {
+	var positional []string
+	for {
+		err := fs.Parse(args)
+		switch {
+		case errors.Is(err, flag.ErrHelp):
+			return ExitOK, false
+		case err != nil:
+			return ExitUsage, false
+		}
+		rest := fs.Args()
+		if len(rest) == 0 {
+			break
+		}
+		if len(rest) < len(args) && args[len(args)-len(rest)-1] == "--" {
+			positional = append(positional, rest...)
+			break
+		}
+		positional, args = append(positional, rest[0]), rest[1:]
+	}
+	// Parse only the arguments again, so that fs.Args returns them.
+	if err := fs.Parse(append([]string{"--"}, positional...)); err != nil {
+		return ExitUsage, false
+	}
+	return ExitOK, true
+}
+
+func (c *cli) fail(err error) int {
+	fmt.Fprintln(c.stderr, err)
+	return ExitError
+}
+
+var errNoKind = errors.New("no kind given and no kinds in the config file")
+
+// setupFailed reports an error of options.bot, a missing kind is a usage error.
+func (c *cli) setupFailed(fs *flag.FlagSet, err error) int {
+	if errors.Is(err, errNoKind) {
+		fmt.Fprintln(c.stderr, err)
+		fs.Usage()
+		return ExitUsage
+	}
+	return c.fail(err)
+}
+
+// options are the flags selecting what is generated, shared by the commands
+// that scan a repository.
+type options struct {
+	kind     string
+	path     string
+	interval string
+	day      string
+	time     string
+	timezone string
+	cronjob  string
+	config   string
+	// pin keeps the staggered schedules of the entries in the existing file,
+	// read from existing or the default locations, unless reshuffle is set.
+	pin       bool
+	existing  string
+	reshuffle bool
+	// allByDefault selects all kinds if neither a kind nor the config file
+	// select any.
+	allByDefault bool
+}
+
+func (o *options) register(fs *flag.FlagSet, schedule bool) {
+	fs.StringVar(&o.kind, "kind", "", "comma separated kinds or all, defaults to the kinds of the config file")
+	fs.StringVar(&o.path, "path", "", "path of the repository (default \".\")")
+	fs.StringVar(&o.config, "config", "", "config file, defaults to .dependabot-templater.yaml of the path or the current directory")
+	if schedule {
+		fs.StringVar(&o.interval, "interval", "", "schedule interval of all entries")
+		fs.StringVar(&o.day, "day", "", "schedule day of weekly entries")
+		fs.StringVar(&o.time, "time", "", "schedule time of all entries, formatted as hh:mm")
+		fs.StringVar(&o.timezone, "timezone", "", "IANA time zone of the schedule time")
+		fs.StringVar(&o.cronjob, "cronjob", "", "five field cron expression of all entries, implies the cron interval")
+		fs.BoolVar(&o.reshuffle, "reshuffle", false, "move staggered entries of the existing config to the slots of their hash")
+		o.pin = true
+	}
+}
+
+// positional fills the options that are not set by flags from args in the
+// order kind, path, interval, day.
+func (o *options) positional(args []string) error {
+	fields := []*string{&o.kind, &o.path, &o.interval, &o.day}
+	if len(args) > len(fields) {
+		return fmt.Errorf("too many arguments: %s", strings.Join(args[len(fields):], " "))
+	}
+	for i, arg := range args {
+		if *fields[i] == "" {
+			*fields[i] = arg
+		}
+	}
+	if o.path == "" {
+		o.path = "."
+	}
+	return nil
+}
+
+// bot loads the settings and creates the generator of the options, warnings
+// are printed to stderr.
+func (o *options) bot(stderr io.Writer) (*settings.Settings, *dependabot.DependaBot, error) {
+	var cfg *settings.Settings
+	var err error
+	if o.config != "" {
+		cfg, err = settings.LoadFile(o.config)
+	} else {
+		cfg, err = settings.Load(o.path, ".")
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	if o.kind == "" && len(cfg.Kinds) == 0 {
+		if !o.allByDefault {
+			return nil, nil, errNoKind
+		}
+		o.kind = "all"
+	}
+
+	opts := []dependabot.Option{
+		dependabot.WithSettings(cfg),
+		dependabot.WithInterval(o.interval),
+		dependabot.WithDay(o.day),
+		dependabot.WithTime(o.time),
+		dependabot.WithTimezone(o.timezone),
+		dependabot.WithCronjob(o.cronjob),
+		dependabot.WithWarnings(func(w dependabot.Warning) {
+			fmt.Fprintf(stderr, "warning: %s\n", w)
+		}),
+	}
+	if o.kind != "" {
+		opts = append(opts, dependabot.WithKind(o.kind))
+	}
+	if o.pin {
+		file := o.existing
+		if file == "" {
+			file = cfg.OutputPath()
+		}
+		data, err := readExisting(file, o.path)
+		if err != nil {
+			return nil, nil, err
+		}
+		// A broken file has no schedules worth keeping, generate replaces it.
+		if existing, err := config.Parse(data); err == nil {
+			opts = append(opts, dependabot.WithExisting(existing), dependabot.WithReshuffle(o.reshuffle))
+		}
+	}
+	return cfg, dependabot.New(opts...), nil
+}
+
+func (c *cli) generate(args []string) int {
+	fs := c.flagSet("generate")
+	var o options
+	o.register(fs, true)
+	write := fs.Bool("write", false, "write the config to .github/dependabot.yml (or the existing .yaml) of the path")
+	output := fs.String("output", "", "write the config to this file")
+	if code, ok := parse(fs, args); !ok {
+		return code
+	}
+	if err := o.positional(fs.Args()); err != nil {
+		fmt.Fprintln(c.stderr, err)
+		fs.Usage()
+		return ExitUsage
+	}
+	o.existing = *output
+
+	cfg, bot, err := o.bot(c.stderr)
+	if err != nil {
+		return c.setupFailed(fs, err)
+	}
+	target := cfg.OutputPath()
+	switch {
+	case *output != "":
+		target = *output
+	case *write && target == "":
+		target = config.Target(o.path)
+	}
+
+	var out string
+	if cfg.Merge {
+		existing, err := readExisting(target, o.path)
+		if err != nil {
+			return c.fail(err)
+		}
+		_, out, err = bot.MergeConfigFile(o.path, existing)
+		if err != nil {
+			return c.fail(err)
+		}
+	} else {
+		_, out, err = bot.GenerateConfigFile(o.path)
+		if err != nil {
+			return c.fail(err)
+		}
+	}
+
+	if target == "" {
+		if _, err := io.WriteString(c.stdout, out); err != nil {
+			return c.fail(err)
+		}
+		return ExitOK
+	}
+	changed, err := config.WriteFile(target, []byte(out))
+	if err != nil {
+		return c.fail(err)
+	}
+	if changed {
+		fmt.Fprintf(c.stderr, "updated %s\n", target)
+		return ExitChanged
+	}
+	return ExitOK
+}
+
+func (c *cli) check(args []string) int {
+	fs := c.flagSet("check")
+	var o options
+	o.register(fs, true)
+	file := fs.String("file", "", "committed config, defaults to .github/dependabot.yml (or .yaml) of the path")
+	if code, ok := parse(fs, args); !ok {
+		return code
+	}
+	if err := o.positional(fs.Args()); err != nil {
+		fmt.Fprintln(c.stderr, err)
+		fs.Usage()
+		return ExitUsage
+	}
+	o.existing = *file
+
+	cfg, bot, err := o.bot(c.stderr)
+	if err != nil {
+		return c.setupFailed(fs, err)
+	}
+	if *file == "" {
+		*file = cfg.OutputPath()
+	}
+	if *file == "" {
+		*file = config.Target(o.path)
+	}
+	committed, err := readExisting(*file, o.path)
+	if err != nil {
+		return c.fail(err)
+	}
+	drift, err := bot.CheckConfigFile(o.path, *file, committed)
+	if err != nil {
+		return c.fail(err)
+	}
+	if drift.HasDrift() {
+		fmt.Fprint(c.stdout, drift.Diff)
+		fmt.Fprintln(c.stdout)
+		fmt.Fprint(c.stdout, drift.Summary())
+		return ExitFailed
+	}
+	return ExitOK
+}
+
+// readExisting reads the dependabot.yml at file or the one of the scanned
+// repository. A missing file is read as empty config.
+func readExisting(file, path string) ([]byte, error) {
+	if file == "" {
+		file = config.Find(path)
+	}
+	if file == "" {
+		return nil, nil
+	}
+	data, err := os.ReadFile(file)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	return data, err
+}
+
+func (c *cli) validate(args []string) int {
+	fs := c.flagSet("validate")
+	if code, ok := parse(fs, args); !ok {
+		return code
+	}
+	files := fs.Args()
+	if len(files) == 0 {
+		file := config.Find(".")
+		if file == "" {
+			return c.fail(fmt.Errorf("no %s found", config.FileNames[0]))
+		}
+		files = []string{file}
+	}
+
+	code := ExitOK
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			c.fail(err)
+			code = max(code, ExitError)
+			continue
+		}
+		if err := config.ValidateFile(file, data); err != nil {
+			fmt.Fprintln(c.stderr, err)
+			code = ExitFailed
+		}
+	}
+	return code
+}
+
+func (c *cli) listKinds(args []string) int {
+	fs := c.flagSet("list-kinds")
+	if code, ok := parse(fs, args); !ok {
+		return code
+	}
+	for _, detector := range dependabot.Detectors() {
+		fmt.Fprintf(c.stdout, "%s\t%s\n", detector.Kind(), detector.Ecosystem())
+	}
+	return ExitOK
+}
+
+func (c *cli) detect(args []string) int {
+	fs := c.flagSet("detect")
+	var o options
+	o.register(fs, false)
+	format := fs.String("format", "text", "output format: text, json or yaml")
+	if code, ok := parse(fs, args); !ok {
+		return code
+	}
+	if err := o.positional(fs.Args()); err != nil {
+		fmt.Fprintln(c.stderr, err)
+		fs.Usage()
+		return ExitUsage
+	}
+	if !validFormat(*format) {
+		fmt.Fprintf(c.stderr, "unknown format %q\n", *format)
+		fs.Usage()
+		return ExitUsage
+	}
+	o.allByDefault = true
+
+	_, bot, err := o.bot(c.stderr)
+	if err != nil {
+		return c.setupFailed(fs, err)
+	}
+	detections, err := bot.Detect(o.path)
+	if err != nil {
+		return c.fail(err)
+	}
+
+	if *format != "text" {
+		if err := c.encode(*format, detections); err != nil {
+			return c.fail(err)
+		}
+		return ExitOK
+	}
+	for _, detection := range detections {
+		for _, folder := range detection.Folders {
+			fmt.Fprintf(c.stdout, "%s\t%s\n", detection.Kind, folder)
+		}
+	}
+	return ExitOK
+}
+
+func validFormat(format string) bool {
+	return slices.Contains([]string{"text", "json", "yaml"}, format)
+}
+
+// encode writes v as json or yaml to stdout.
+func (c *cli) encode(format string, v any) error {
+	if format == "json" {
+		enc := json.NewEncoder(c.stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(v)
+	}
+	enc := yaml.NewEncoder(c.stdout)
+	enc.SetIndent(2)
+	if err := enc.Encode(v); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+func (c *cli) explain(args []string) int {
+	fs := c.flagSet("explain")
+	var o options
+	o.register(fs, false)
+	format := fs.String("format", "text", "output format: text, json or yaml")
+	if code, ok := parse(fs, args); !ok {
+		return code
+	}
+	if fs.NArg() != 1 || !validFormat(*format) {
+		fs.Usage()
+		return ExitUsage
+	}
+	dir := fs.Arg(0)
+	if o.path == "" {
+		o.path = "."
+	}
+	o.allByDefault = true
+
+	_, bot, err := o.bot(c.stderr)
+	if err != nil {
+		return c.setupFailed(fs, err)
+	}
+	explanations, err := bot.Explain(o.path, dir)
+	if err != nil {
+		return c.fail(err)
+	}
+	if *format != "text" {
+		if err := c.encode(*format, explanations); err != nil {
+			return c.fail(err)
+		}
+		return ExitOK
+	}
+
+	for _, e := range explanations {
+		state := "not generated"
+		if e.Generated {
+			state = "generated"
+		}
+		if e.Reason != "" {
+			state += ", " + e.Reason
+		}
+		fmt.Fprintf(c.stdout, "%s: %s\n", e.Kind, state)
+		for _, match := range e.Matches {
+			fmt.Fprintf(c.stdout, "  %s: %s\n", match.File, match.Rule)
+		}
+	}
+	return ExitOK
+}
@@ -0,0 +1,200 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func run(t *testing.T, args ...string) (int, string, string) {
+	t.Helper()
+	var stdout, stderr bytes.Buffer
+	code := Run(args, &stdout, &stderr)
+	return code, stdout.String(), stderr.String()
+}
+
+// repo creates a repository with a go module and a Dockerfile and changes
+// into it.
+func repo(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	t.Chdir(dir)
+	for _, file := range []string{"api/go.mod", "web/Dockerfile"} {
+		require.NoError(t, os.MkdirAll(filepath.Join(dir, filepath.Dir(file)), 0o755))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, file), nil, 0o644))
+	}
+	return "."
+}
+
+func TestUsage(t *testing.T) {
+	code, _, stderr := run(t)
+	assert.Equal(t, ExitUsage, code)
+	assert.Contains(t, stderr, "no kind given")
+	assert.Contains(t, stderr, "usage: dependabot-templater generate")
+
+	code, stdout, _ := run(t, "--help")
+	assert.Equal(t, ExitOK, code)
+	assert.Contains(t, stdout, "list-kinds")
+
+	code, _, stderr = run(t, "check", "--help")
+	assert.Equal(t, ExitOK, code)
+	assert.Contains(t, stderr, "-file")
+
+	code, _, _ = run(t, "generate", "--unknown")
+	assert.Equal(t, ExitUsage, code)
+}
+
+func TestGenerate(t *testing.T) {
+	dir := repo(t)
+
+	code, positional, _ := run(t, "go", dir, "daily")
+	assert.Equal(t, ExitOK, code)
+	assert.Contains(t, positional, "package-ecosystem: gomod")
+	assert.Contains(t, positional, "interval: daily")
+
+	code, flags, _ := run(t, "generate", "--kind", "go", "--path", dir, "--interval", "daily")
+	assert.Equal(t, ExitOK, code)
+	assert.Equal(t, positional, flags)
+
+	code, mixed, _ := run(t, "generate", "go", "--interval", "daily", dir)
+	assert.Equal(t, ExitOK, code)
+	assert.Equal(t, positional, mixed)
+
+	code, stdout, _ := run(t, "generate", "--time", "06:30", "--timezone", "Europe/Berlin", "go", dir)
+	assert.Equal(t, ExitOK, code)
+	assert.Contains(t, stdout, "      time: \"06:30\"\n      timezone: Europe/Berlin\n")
+
+	code, _, stderr := run(t, "generate", "unknown", dir)
+	assert.Equal(t, ExitError, code)
+	assert.Contains(t, stderr, `unknown kind "unknown"`)
+}
+
+func TestWriteAndCheck(t *testing.T) {
+	dir := repo(t)
+
+	code, _, _ := run(t, "generate", "--write", "go", dir)
+	assert.Equal(t, ExitChanged, code)
+	assert.FileExists(t, filepath.Join(dir, ".github", "dependabot.yml"))
+	code, _, _ = run(t, "generate", "go", dir, "--write")
+	assert.Equal(t, ExitOK, code)
+
+	code, _, _ = run(t, "check", "go", dir)
+	assert.Equal(t, ExitOK, code)
+	code, stdout, _ := run(t, "check", "go,docker", dir)
+	assert.Equal(t, ExitFailed, code)
+	assert.Contains(t, stdout, `missing: docker in "web"`)
+
+	code, _, _ = run(t, "validate", filepath.Join(dir, ".github", "dependabot.yml"))
+	assert.Equal(t, ExitOK, code)
+	code, _, stderr := run(t, "validate", filepath.Join(dir, "api", "go.mod"))
+	assert.Equal(t, ExitFailed, code)
+	assert.Contains(t, stderr, "version must be 2")
+}
+
+func TestCodeOwnersWarning(t *testing.T) {
+	dir := repo(t)
+	require.NoError(t, os.WriteFile("CODEOWNERS", []byte("/api/ @alice\n"), 0o644))
+	require.NoError(t, os.WriteFile(".dependabot-templater.yaml", []byte("codeowners:\n  reviewers: true\n"), 0o644))
+
+	code, stdout, stderr := run(t, "generate", "--kind", "go,docker", "--path", dir)
+	assert.Equal(t, ExitOK, code)
+	assert.Contains(t, stdout, "reviewers:\n      - alice\n")
+	assert.Equal(t, "warning: docker in web: directory has no owner in CODEOWNERS\n", stderr)
+}
+
+func TestStaggerReshuffle(t *testing.T) {
+	dir := repo(t)
+	require.NoError(t, os.WriteFile(".dependabot-templater.yaml", []byte(`kinds: [go]
+output: .github/dependabot.yml
+schedule:
+  stagger:
+    from: "06:00"
+    to: "09:00"
+`), 0o644))
+
+	code, _, _ := run(t, "generate", "--path", dir)
+	assert.Equal(t, ExitChanged, code)
+	generated, err := os.ReadFile(".github/dependabot.yml")
+	require.NoError(t, err)
+
+	// Move the entry to another slot inside the window.
+	pinned := regexp.MustCompile(`time: "\d\d:\d\d"`).ReplaceAll(generated, []byte(`time: "08:59"`))
+	require.NoError(t, os.WriteFile(".github/dependabot.yml", pinned, 0o644))
+	code, _, _ = run(t, "generate", "--path", dir)
+	assert.Equal(t, ExitOK, code)
+	code, _, _ = run(t, "check", "--path", dir)
+	assert.Equal(t, ExitOK, code)
+	code, _, _ = run(t, "check", "--reshuffle", "--path", dir)
+	assert.Equal(t, ExitFailed, code)
+
+	code, _, _ = run(t, "generate", "--reshuffle", "--path", dir)
+	assert.Equal(t, ExitChanged, code)
+	reshuffled, err := os.ReadFile(".github/dependabot.yml")
+	require.NoError(t, err)
+	assert.Equal(t, string(generated), string(reshuffled))
+}
+
+func TestListKindsDetectExplain(t *testing.T) {
+	code, stdout, _ := run(t, "list-kinds")
+	assert.Equal(t, ExitOK, code)
+	assert.Contains(t, stdout, "go\tgomod\n")
+
+	dir := repo(t)
+	code, stdout, _ = run(t, "detect", "--path", dir)
+	assert.Equal(t, ExitOK, code)
+	assert.Equal(t, "docker\tweb\ngo\tapi\n", stdout)
+
+	code, stdout, _ = run(t, "explain", "--path", dir, "api")
+	assert.Equal(t, ExitOK, code)
+	assert.Contains(t, stdout, "go: generated\n  api/go.mod: file name is one of [go.mod]\n")
+	assert.Contains(t, stdout, "docker: not generated, no file matches file name is one of [Dockerfile]\n")
+
+	code, stdout, _ = run(t, "explain", "--path", dir, "--kind", "go", "--format", "json", "api")
+	assert.Equal(t, ExitOK, code)
+	assert.JSONEq(t, `[{"kind":"go","generated":true,"matches":[{"file":"api/go.mod","rule":"file name is one of [go.mod]"}]}]`, stdout)
+
+	code, _, _ = run(t, "explain")
+	assert.Equal(t, ExitUsage, code)
+}
+
+func TestCompletion(t *testing.T) {
+	for _, shell := range []string{"bash", "zsh", "fish"} {
+		code, stdout, _ := run(t, "completion", shell)
+		assert.Equal(t, ExitOK, code, shell)
+		assert.Contains(t, stdout, "list-kinds", shell)
+	}
+	code, _, _ := run(t, "completion", "tcsh")
+	assert.Equal(t, ExitUsage, code)
+}
+
+func TestDetectFormats(t *testing.T) {
+	repo(t)
+
+	code, stdout, _ := run(t, "detect", "--format", "json", "go")
+	assert.Equal(t, ExitOK, code)
+	assert.JSONEq(t, `[{"kind": "go", "ecosystem": "gomod", "folders": ["api"], "files": ["api/go.mod"]}]`, stdout)
+
+	code, stdout, _ = run(t, "detect", "--format", "yaml")
+	assert.Equal(t, ExitOK, code)
+	assert.Equal(t, `- kind: docker
+  ecosystem: docker
+  folders:
+    - web
+  files:
+    - web/Dockerfile
+- kind: go
+  ecosystem: gomod
+  folders:
+    - api
+  files:
+    - api/go.mod
+`, stdout)
+
+	code, _, _ = run(t, "detect", "--format", "xml")
+	assert.Equal(t, ExitUsage, code)
+}
@@ -0,0 +1,107 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+)
+
+const bashCompletion = `# bash completion for dependabot-templater
+_dependabot_templater() {
+    local cur prev
+    cur="${COMP_WORDS[COMP_CWORD]}"
+    prev="${COMP_WORDS[COMP_CWORD-1]}"
+    if [ "$COMP_CWORD" -eq 1 ]; then
+        COMPREPLY=($(compgen -W "%[1]s $(dependabot-templater list-kinds 2>/dev/null | cut -f1)" -- "$cur"))
+        return
+    fi
+    case "$prev" in
+    --kind|-kind)
+        COMPREPLY=($(compgen -W "all $(dependabot-templater list-kinds 2>/dev/null | cut -f1)" -- "$cur"))
+        return
+        ;;
+    completion)
+        COMPREPLY=($(compgen -W "bash zsh fish" -- "$cur"))
+        return
+        ;;
+    esac
+    if [[ "$cur" == -* ]]; then
+        COMPREPLY=($(compgen -W "%[2]s" -- "$cur"))
+        return
+    fi
+    COMPREPLY=($(compgen -f -- "$cur"))
+}
+complete -o filenames -F _dependabot_templater dependabot-templater
+`
+
+const zshCompletion = `#compdef dependabot-templater
+# zsh completion for dependabot-templater
+_dependabot_templater() {
+    if (( CURRENT == 2 )); then
+        compadd -- %[1]s ${(f)"$(dependabot-templater list-kinds 2>/dev/null | cut -f1)"}
+        return
+    fi
+    case "${words[CURRENT-1]}" in
+    --kind|-kind)
+        compadd -- all ${(f)"$(dependabot-templater list-kinds 2>/dev/null | cut -f1)"}
+        return
+        ;;
+    completion)
+        compadd -- bash zsh fish
+        return
+        ;;
+    esac
+    if [[ "${words[CURRENT]}" == -* ]]; then
+        compadd -- %[2]s
+        return
+    fi
+    _files
+}
+compdef _dependabot_templater dependabot-templater
+`
+
+const fishCompletion = `# fish completion for dependabot-templater
+complete -c dependabot-templater -n '__fish_is_first_arg' -f -a '%[1]s'
+complete -c dependabot-templater -n '__fish_is_first_arg' -f -a '(dependabot-templater list-kinds 2>/dev/null | cut -f1)'
+complete -c dependabot-templater -n '__fish_seen_subcommand_from completion' -f -a 'bash zsh fish'
+complete -c dependabot-templater -l kind -x -a 'all (dependabot-templater list-kinds 2>/dev/null | cut -f1)'
+%[2]s`
+
+// flagNames are the flags offered by the completion scripts.
+var flagNames = []string{"kind", "path", "config", "interval", "day", "time", "timezone", "cronjob", "reshuffle", "write", "output", "file", "help"}
+
+func (c *cli) completion(args []string) int {
+	fs := c.flagSet("completion")
+	if code, ok := parse(fs, args); !ok {
+		return code
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return ExitUsage
+	}
+
+	names := make([]string, len(commands))
+	for i, cmd := range commands {
+		names[i] = cmd.name
+	}
+	flags := make([]string, len(flagNames))
+	for i, name := range flagNames {
+		flags[i] = "--" + name
+	}
+
+	switch fs.Arg(0) {
+	case "bash":
+		fmt.Fprintf(c.stdout, bashCompletion, strings.Join(names, " "), strings.Join(flags, " "))
+	case "zsh":
+		fmt.Fprintf(c.stdout, zshCompletion, strings.Join(names, " "), strings.Join(flags, " "))
+	case "fish":
+		var long strings.Builder
+		for _, name := range flagNames {
+			fmt.Fprintf(&long, "complete -c dependabot-templater -l %s\n", name)
+		}
+		fmt.Fprintf(c.stdout, fishCompletion, strings.Join(names, " "), long.String())
+	default:
+		fmt.Fprintf(c.stderr, "unsupported shell %q, expected bash, zsh or fish\n", fs.Arg(0))
+		return ExitUsage
+	}
+	return ExitOK
+}
@@ -0,0 +1,130 @@
+// Package codeowners parses CODEOWNERS files and looks up the owners of paths
+// with the semantics GitHub applies: the last matching rule wins.
+package codeowners
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/containifyci/dependabot-templater/pkg/search"
+)
+
+// FileNames are the locations GitHub looks up the CODEOWNERS file in, relative
+// to the repository root and in the order GitHub uses.
+var FileNames = []string{".github/CODEOWNERS", "CODEOWNERS", "docs/CODEOWNERS"}
+
+// Rule is a line of a CODEOWNERS file.
+type Rule struct {
+	Pattern string
+	// Owners are users (@user), teams (@org/team) or email addresses. A rule
+	// without owners removes the ownership of the matching paths.
+	Owners []string
+	Line   int
+}
+
+// File is a parsed CODEOWNERS file.
+type File struct {
+	Rules []Rule
+}
+
+// Find returns the path of the CODEOWNERS file of the repository in dir or an
+// empty string if there is none.
+func Find(dir string) string {
+	for _, name := range FileNames {
+		file := filepath.Join(dir, name)
+		if info, err := os.Stat(file); err == nil && !info.IsDir() {
+			return file
+		}
+	}
+	return ""
+}
+
+func Load(file string) (*File, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return Parse(f)
+}
+
+// Parse reads the rules of a CODEOWNERS file. Comments and blank lines are
+// skipped, a "\#" starts a pattern with a literal "#".
+func Parse(r io.Reader) (*File, error) {
+	f := &File{}
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		if i := strings.Index(text, " #"); i >= 0 {
+			text = text[:i]
+		}
+		fields := strings.Fields(text)
+		pattern := strings.ReplaceAll(fields[0], `\#`, "#")
+		if strings.HasPrefix(pattern, "!") || strings.ContainsAny(pattern, "[]") {
+			return nil, fmt.Errorf("line %d: unsupported pattern %q, CODEOWNERS doesn't support negation and character ranges", line, pattern)
+		}
+		f.Rules = append(f.Rules, Rule{Pattern: pattern, Owners: fields[1:], Line: line})
+	}
+	return f, scanner.Err()
+}
+
+// Owners returns the owners of the last rule matching p, a slash separated
+// path relative to the repository root. dir reports whether p is a directory.
+func (f *File) Owners(p string, dir bool) []string {
+	if rule, ok := f.Match(p, dir); ok {
+		return rule.Owners
+	}
+	return nil
+}
+
+// Match returns the last rule matching p.
+func (f *File) Match(p string, dir bool) (Rule, bool) {
+	p = strings.Trim(path.Clean("/"+filepath.ToSlash(p)), "/")
+	for i := len(f.Rules) - 1; i >= 0; i-- {
+		if matches(f.Rules[i].Pattern, p, dir) {
+			return f.Rules[i], true
+		}
+	}
+	return Rule{}, false
+}
+
+// matches follows the gitignore rules: patterns with a leading or inner slash
+// are relative to the root, others match at any depth. A pattern matching a
+// directory matches everything below it, except for patterns ending in "/*"
+// that only match the direct children.
+func matches(pattern, p string, dir bool) bool {
+	dirOnly := strings.HasSuffix(pattern, "/")
+	pattern = strings.TrimSuffix(pattern, "/")
+	if pattern == "" {
+		return false
+	}
+	if !strings.Contains(pattern, "/") {
+		pattern = "**/" + pattern
+	}
+	pattern = strings.TrimPrefix(pattern, "/")
+	if p == "" || p == "." {
+		return pattern == "**/*"
+	}
+
+	if search.MatchGlob(pattern, p) && (dir || !dirOnly) {
+		return true
+	}
+	if strings.HasSuffix(pattern, "/*") {
+		return false
+	}
+	// Ancestors are always directories.
+	for parent := path.Dir(p); parent != "."; parent = path.Dir(parent) {
+		if search.MatchGlob(pattern, parent) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,79 @@
+package codeowners
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const example = `# Default owners
+*                   @acme/platform
+
+*.js                @acme/frontend   # inline comment
+/build/logs/        @octocat
+docs/*              docs@example.com
+apps/               @acme/apps
+/services/payments  @acme/payments @alice
+**/generated        @acme/codegen
+/services/payments/legacy
+\#notes             @bob
+`
+
+func TestOwners(t *testing.T) {
+	f, err := Parse(strings.NewReader(example))
+	require.NoError(t, err)
+	require.Len(t, f.Rules, 9)
+	assert.Equal(t, Rule{Pattern: "*.js", Owners: []string{"@acme/frontend"}, Line: 4}, f.Rules[1])
+
+	for _, test := range []struct {
+		path   string
+		dir    bool
+		owners []string
+	}{
+		{".", true, []string{"@acme/platform"}},
+		{"README.md", false, []string{"@acme/platform"}},
+		{"web/app.js", false, []string{"@acme/frontend"}},
+		{"build/logs", true, []string{"@octocat"}},
+		{"build/logs/today/out.txt", false, []string{"@octocat"}},
+		{"build/logs", false, []string{"@acme/platform"}},
+		{"docs/index.md", false, []string{"docs@example.com"}},
+		{"docs/guides/setup.md", false, []string{"@acme/platform"}},
+		{"apps", true, []string{"@acme/apps"}},
+		{"tools/apps/cli", true, []string{"@acme/apps"}},
+		{"services/payments", true, []string{"@acme/payments", "@alice"}},
+		{"./services/payments/api/", true, []string{"@acme/payments", "@alice"}},
+		{"lib/services/payments", true, []string{"@acme/platform"}},
+		{"services/payments/legacy/db", true, []string{}},
+		{"apps/web/generated", true, []string{"@acme/codegen"}},
+		{"#notes", false, []string{"@bob"}},
+	} {
+		assert.Equal(t, test.owners, f.Owners(test.path, test.dir), test.path)
+	}
+}
+
+func TestParseUnsupported(t *testing.T) {
+	_, err := Parse(strings.NewReader("*  @acme/platform\n!vendor/\n"))
+	assert.EqualError(t, err, `line 2: unsupported pattern "!vendor/", CODEOWNERS doesn't support negation and character ranges`)
+}
+
+func TestFind(t *testing.T) {
+	dir := t.TempDir()
+	assert.Empty(t, Find(dir))
+
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "docs"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "docs", "CODEOWNERS"), []byte("* @docs\n"), 0o644))
+	assert.Equal(t, filepath.Join(dir, "docs", "CODEOWNERS"), Find(dir))
+
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, ".github"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ".github", "CODEOWNERS"), []byte("* @github\n"), 0o644))
+	file := Find(dir)
+	assert.Equal(t, filepath.Join(dir, ".github", "CODEOWNERS"), file)
+
+	f, err := Load(file)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"@github"}, f.Owners("main.go", false))
+}
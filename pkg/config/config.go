@@ -0,0 +1,210 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Header is written in front of every marshalled config.
+const Header = `---
+# https://docs.github.com/github/administering-a-repository/configuration-options-for-dependency-updates
+`
+
+// Config is a dependabot.yml version 2 file.
+type Config struct {
+	Version              int                            `yaml:"version"`
+	EnableBetaEcosystems bool                           `yaml:"enable-beta-ecosystems,omitempty"`
+	Registries           map[string]Registry            `yaml:"registries,omitempty"`
+	MultiEcosystemGroups map[string]MultiEcosystemGroup `yaml:"multi-ecosystem-groups,omitempty"`
+	Updates              []Update                       `yaml:"updates"`
+}
+
+type Registry struct {
+	Type                 string `yaml:"type"`
+	URL                  string `yaml:"url,omitempty"`
+	Username             string `yaml:"username,omitempty"`
+	Password             string `yaml:"password,omitempty"`
+	Key                  string `yaml:"key,omitempty"`
+	Token                string `yaml:"token,omitempty"`
+	Organization         string `yaml:"organization,omitempty"`
+	Repo                 string `yaml:"repo,omitempty"`
+	AuthKey              string `yaml:"auth-key,omitempty"`
+	PublicKeyFingerprint string `yaml:"public-key-fingerprint,omitempty"`
+	ReplacesBase         bool   `yaml:"replaces-base,omitempty"`
+}
+
+type MultiEcosystemGroup struct {
+	Schedule              Schedule       `yaml:"schedule"`
+	Labels                []string       `yaml:"labels,omitempty"`
+	Assignees             []string       `yaml:"assignees,omitempty"`
+	Milestone             int            `yaml:"milestone,omitempty"`
+	TargetBranch          string         `yaml:"target-branch,omitempty"`
+	CommitMessage         *CommitMessage `yaml:"commit-message,omitempty"`
+	PullRequestBranchName *BranchName    `yaml:"pull-request-branch-name,omitempty"`
+}
+
+type Update struct {
+	PackageEcosystem              string           `yaml:"package-ecosystem"`
+	Directory                     string           `yaml:"directory,omitempty"`
+	Directories                   []string         `yaml:"directories,omitempty"`
+	Schedule                      Schedule         `yaml:"schedule"`
+	RebaseStrategy                string           `yaml:"rebase-strategy,omitempty"`
+	OpenPullRequestsLimit         *int             `yaml:"open-pull-requests-limit,omitempty"`
+	CommitMessage                 *CommitMessage   `yaml:"commit-message,omitempty"`
+	InsecureExternalCodeExecution string           `yaml:"insecure-external-code-execution,omitempty"`
+	Registries                    StringList       `yaml:"registries,omitempty"`
+	Groups                        map[string]Group `yaml:"groups,omitempty"`
+	Ignore                        []Ignore         `yaml:"ignore,omitempty"`
+	Allow                         []Allow          `yaml:"allow,omitempty"`
+	Labels                        []string         `yaml:"labels,omitempty"`
+	Assignees                     []string         `yaml:"assignees,omitempty"`
+	Reviewers                     []string         `yaml:"reviewers,omitempty"`
+	Milestone                     int              `yaml:"milestone,omitempty"`
+	TargetBranch                  string           `yaml:"target-branch,omitempty"`
+	PullRequestBranchName         *BranchName      `yaml:"pull-request-branch-name,omitempty"`
+	Cooldown                      *Cooldown        `yaml:"cooldown,omitempty"`
+	VersioningStrategy            string           `yaml:"versioning-strategy,omitempty"`
+	Vendor                        bool             `yaml:"vendor,omitempty"`
+	ExcludePaths                  []string         `yaml:"exclude-paths,omitempty"`
+	MultiEcosystemGroup           string           `yaml:"multi-ecosystem-group,omitempty"`
+	Patterns                      []string         `yaml:"patterns,omitempty"`
+}
+
+type Schedule struct {
+	Interval string `yaml:"interval"`
+	Cronjob  string `yaml:"cronjob,omitempty"`
+	Day      string `yaml:"day,omitempty"`
+	Time     string `yaml:"time,omitempty"`
+	Timezone string `yaml:"timezone,omitempty"`
+}
+
+type CommitMessage struct {
+	Prefix            string `yaml:"prefix,omitempty"`
+	PrefixDevelopment string `yaml:"prefix-development,omitempty"`
+	Include           string `yaml:"include,omitempty"`
+}
+
+type Group struct {
+	AppliesTo       string   `yaml:"applies-to,omitempty"`
+	DependencyType  string   `yaml:"dependency-type,omitempty"`
+	Patterns        []string `yaml:"patterns,omitempty"`
+	ExcludePatterns []string `yaml:"exclude-patterns,omitempty"`
+	UpdateTypes     []string `yaml:"update-types,omitempty"`
+	GroupBy         string   `yaml:"group-by,omitempty"`
+}
+
+type Ignore struct {
+	DependencyName string   `yaml:"dependency-name"`
+	Versions       []string `yaml:"versions,omitempty"`
+	UpdateTypes    []string `yaml:"update-types,omitempty"`
+}
+
+type Allow struct {
+	DependencyName string `yaml:"dependency-name,omitempty"`
+	DependencyType string `yaml:"dependency-type,omitempty"`
+}
+
+type BranchName struct {
+	Separator string `yaml:"separator"`
+}
+
+// Cooldown days are pointers so an explicit 0 is kept apart from unset.
+type Cooldown struct {
+	DefaultDays     *int     `yaml:"default-days,omitempty"`
+	SemverMajorDays *int     `yaml:"semver-major-days,omitempty"`
+	SemverMinorDays *int     `yaml:"semver-minor-days,omitempty"`
+	SemverPatchDays *int     `yaml:"semver-patch-days,omitempty"`
+	Include         []string `yaml:"include,omitempty"`
+	Exclude         []string `yaml:"exclude,omitempty"`
+}
+
+// StringList is a list of strings that is also read from a single scalar,
+// like `registries: "*"`.
+type StringList []string
+
+func (l *StringList) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		*l = StringList{node.Value}
+		return nil
+	}
+	var list []string
+	if err := node.Decode(&list); err != nil {
+		return err
+	}
+	*l = list
+	return nil
+}
+
+func (l StringList) MarshalYAML() (any, error) {
+	if len(l) == 1 && l[0] == "*" {
+		return l[0], nil
+	}
+	return []string(l), nil
+}
+
+// New returns an empty version 2 config.
+func New() *Config {
+	return &Config{Version: 2, Updates: []Update{}}
+}
+
+// Marshal renders the config with the Header. Maps are sorted by key, so the
+// output only depends on the content of the config.
+func (c *Config) Marshal() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString(Header)
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(c); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Parse reads a dependabot.yml. Unknown keys are ignored.
+func Parse(data []byte) (*Config, error) {
+	c := &Config{}
+	err := yaml.NewDecoder(bytes.NewReader(data)).Decode(c)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Key identifies an update entry, Dependabot rejects two entries with the same key.
+type Key struct {
+	Ecosystem    string
+	Directory    string
+	TargetBranch string
+}
+
+func (u Update) Key() Key {
+	dir := u.Directory
+	if dir == "" && len(u.Directories) > 0 {
+		dir = u.Directories[0]
+	}
+	return Key{Ecosystem: u.PackageEcosystem, Directory: dir, TargetBranch: u.TargetBranch}
+}
+
+// FileNames are the locations of dependabot.yml Dependabot reads, relative to
+// the repository root.
+var FileNames = []string{".github/dependabot.yml", ".github/dependabot.yaml"}
+
+// Find returns the path of the dependabot.yml in dir or an empty string if
+// there is none.
+func Find(dir string) string {
+	for _, name := range FileNames {
+		path := filepath.Join(dir, name)
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+	}
+	return ""
+}
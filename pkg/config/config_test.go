@@ -0,0 +1,74 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMarshal(t *testing.T) {
+	limit := 1
+	cfg := New()
+	cfg.Registries = map[string]Registry{
+		"npm": {Type: "npm-registry", URL: "https://npm.example.com", Token: "${{ secrets.NPM_TOKEN }}"},
+	}
+	cfg.Updates = append(cfg.Updates, Update{
+		PackageEcosystem:      "npm",
+		Directory:             "/",
+		Schedule:              Schedule{Interval: "weekly", Day: "monday"},
+		OpenPullRequestsLimit: &limit,
+		Registries:            StringList{"*"},
+		Groups:                map[string]Group{"minor": {Patterns: []string{"*"}, UpdateTypes: []string{"minor", "patch"}}},
+	})
+
+	out, err := cfg.Marshal()
+	require.NoError(t, err)
+	assert.Equal(t, Header+`version: 2
+registries:
+  npm:
+    type: npm-registry
+    url: https://npm.example.com
+    token: ${{ secrets.NPM_TOKEN }}
+updates:
+  - package-ecosystem: npm
+    directory: /
+    schedule:
+      interval: weekly
+      day: monday
+    open-pull-requests-limit: 1
+    registries: '*'
+    groups:
+      minor:
+        patterns:
+          - '*'
+        update-types:
+          - minor
+          - patch
+`, string(out))
+
+	parsed, err := Parse(out)
+	require.NoError(t, err)
+	assert.Equal(t, cfg, parsed)
+}
+
+func TestParse(t *testing.T) {
+	cfg, err := Parse([]byte(`version: 2
+unknown: true
+updates:
+  - package-ecosystem: gomod
+    directories: ["/a", "/b"]
+    target-branch: develop
+    registries: [one, two]
+    schedule:
+      interval: daily
+`))
+	require.NoError(t, err)
+	require.Len(t, cfg.Updates, 1)
+	assert.Equal(t, StringList{"one", "two"}, cfg.Updates[0].Registries)
+	assert.Equal(t, Key{Ecosystem: "gomod", Directory: "/a", TargetBranch: "develop"}, cfg.Updates[0].Key())
+
+	cfg, err = Parse(nil)
+	require.NoError(t, err)
+	assert.Empty(t, cfg.Updates)
+}
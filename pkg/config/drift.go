@@ -0,0 +1,134 @@
+package config
+
+import (
+	"bytes"
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/pmezard/go-difflib/difflib"
+	"gopkg.in/yaml.v3"
+)
+
+// Drift is the semantic difference between a committed and an expected config.
+// The order of entries and formatting are ignored.
+type Drift struct {
+	// Missing are expected entries that are not committed.
+	Missing []Key
+	// Extra are committed entries that are not expected.
+	Extra []Key
+	// Changed are entries with different settings.
+	Changed []Key
+	// Registries are the names of registries that differ.
+	Registries []string
+	// Diff is the unified diff of the normalized configs.
+	Diff string
+}
+
+// HasDrift reports whether the configs differ.
+func (d *Drift) HasDrift() bool {
+	return len(d.Missing)+len(d.Extra)+len(d.Changed)+len(d.Registries) > 0 || d.Diff != ""
+}
+
+// Summary lists the missing, extra and changed entries one per line.
+func (d *Drift) Summary() string {
+	var b strings.Builder
+	for _, key := range d.Missing {
+		fmt.Fprintf(&b, "missing: %s\n", key)
+	}
+	for _, key := range d.Extra {
+		fmt.Fprintf(&b, "extra: %s\n", key)
+	}
+	for _, key := range d.Changed {
+		fmt.Fprintf(&b, "changed: %s\n", key)
+	}
+	for _, name := range d.Registries {
+		fmt.Fprintf(&b, "changed: registry %s\n", name)
+	}
+	return b.String()
+}
+
+// Compare returns the drift between the committed and the expected config,
+// name is used as file name in the diff.
+func Compare(name string, committed, expected *Config) (*Drift, error) {
+	committed, expected = normalize(committed), normalize(expected)
+	d := &Drift{}
+
+	committedUpdates := make(map[Key]Update, len(committed.Updates))
+	for _, u := range committed.Updates {
+		committedUpdates[u.Key()] = u
+	}
+	expectedUpdates := make(map[Key]Update, len(expected.Updates))
+	for _, u := range expected.Updates {
+		key := u.Key()
+		expectedUpdates[key] = u
+		c, ok := committedUpdates[key]
+		switch {
+		case !ok:
+			d.Missing = append(d.Missing, key)
+		case !sameYAML(c, u):
+			d.Changed = append(d.Changed, key)
+		}
+	}
+	for _, u := range committed.Updates {
+		if _, ok := expectedUpdates[u.Key()]; !ok {
+			d.Extra = append(d.Extra, u.Key())
+		}
+	}
+	for _, name := range sortedKeys(expected.Registries) {
+		if c, ok := committed.Registries[name]; !ok || !sameYAML(c, expected.Registries[name]) {
+			d.Registries = append(d.Registries, name)
+		}
+	}
+	for _, name := range sortedKeys(committed.Registries) {
+		if _, ok := expected.Registries[name]; !ok {
+			d.Registries = append(d.Registries, name)
+		}
+	}
+
+	a, err := committed.Marshal()
+	if err != nil {
+		return nil, err
+	}
+	b, err := expected.Marshal()
+	if err != nil {
+		return nil, err
+	}
+	d.Diff, err = difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(string(a)),
+		B:        difflib.SplitLines(string(b)),
+		FromFile: name,
+		ToFile:   name + " (generated)",
+		Context:  3,
+	})
+	return d, err
+}
+
+// sameYAML compares the marshalled values, so nil and empty lists are equal.
+func sameYAML(a, b any) bool {
+	ya, errA := yaml.Marshal(a)
+	yb, errB := yaml.Marshal(b)
+	return errA == nil && errB == nil && bytes.Equal(ya, yb)
+}
+
+// normalize returns a copy of c with the updates sorted by key.
+func normalize(c *Config) *Config {
+	n := *c
+	if len(n.Registries) == 0 {
+		n.Registries = nil
+	}
+	n.Updates = slices.Clone(c.Updates)
+	if n.Updates == nil {
+		n.Updates = []Update{}
+	}
+	slices.SortStableFunc(n.Updates, func(a, b Update) int {
+		ka, kb := a.Key(), b.Key()
+		return cmp.Or(
+			cmp.Compare(ka.Ecosystem, kb.Ecosystem),
+			cmp.Compare(ka.Directory, kb.Directory),
+			cmp.Compare(ka.TargetBranch, kb.TargetBranch),
+		)
+	})
+	return &n
+}
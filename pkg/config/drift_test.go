@@ -0,0 +1,54 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompare(t *testing.T) {
+	committed, err := Parse([]byte(`version: 2
+updates:
+  - package-ecosystem: "npm"
+    directory: "/web"
+    registries: []
+    schedule: {interval: monthly}
+  - package-ecosystem: gomod
+    directory: /
+    schedule:
+      interval: weekly
+      day: sunday
+  - package-ecosystem: docker
+    directory: /legacy
+    schedule:
+      interval: weekly
+`))
+	require.NoError(t, err)
+
+	expected := generatedConfig()
+	drift, err := Compare("dependabot.yml", committed, expected)
+	require.NoError(t, err)
+	assert.True(t, drift.HasDrift())
+	assert.Equal(t, []Key{{Ecosystem: "pip", Directory: "/tools"}}, drift.Missing)
+	assert.Equal(t, []Key{{Ecosystem: "docker", Directory: "/legacy"}}, drift.Extra)
+	assert.Equal(t, []Key{{Ecosystem: "npm", Directory: "/web"}}, drift.Changed)
+	assert.Equal(t, []string{"npm"}, drift.Registries)
+	assert.Equal(t, `missing: pip in "/tools"
+extra: docker in "/legacy"
+changed: npm in "/web"
+changed: registry npm
+`, drift.Summary())
+	assert.Contains(t, drift.Diff, "--- dependabot.yml\n+++ dependabot.yml (generated)\n")
+	assert.Contains(t, drift.Diff, "-  - package-ecosystem: docker\n")
+
+	out, err := expected.Marshal()
+	require.NoError(t, err)
+	committed, err = Parse(out)
+	require.NoError(t, err)
+	committed.Updates[0], committed.Updates[2] = committed.Updates[2], committed.Updates[0]
+	drift, err = Compare("dependabot.yml", committed, expected)
+	require.NoError(t, err)
+	assert.False(t, drift.HasDrift())
+	assert.Empty(t, drift.Diff)
+}
@@ -0,0 +1,74 @@
+package config
+
+import (
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Error is a single problem of a YAML file.
+type Error struct {
+	File string
+	Line int
+	// Path is the path of the offending value, e.g. "updates[0].schedule.day".
+	Path string
+	Msg  string
+}
+
+func (e Error) Error() string {
+	var b strings.Builder
+	if e.File != "" {
+		b.WriteString(e.File)
+		b.WriteString(":")
+	}
+	if e.Line > 0 {
+		b.WriteString(strconv.Itoa(e.Line))
+		b.WriteString(":")
+	}
+	if b.Len() > 0 {
+		b.WriteString(" ")
+	}
+	if e.Path != "" {
+		b.WriteString(e.Path)
+		b.WriteString(": ")
+	}
+	b.WriteString(e.Msg)
+	return b.String()
+}
+
+// Errors are all problems found in a YAML file.
+type Errors []Error
+
+func (e Errors) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "\n")
+}
+
+var yamlLine = regexp.MustCompile(`line (\d+): (.*)`)
+
+// ParseError converts the errors of the yaml decoder into Errors.
+func ParseError(file string, err error) error {
+	var typeErr *yaml.TypeError
+	if errors.As(err, &typeErr) {
+		errs := make(Errors, 0, len(typeErr.Errors))
+		for _, msg := range typeErr.Errors {
+			errs = append(errs, lineError(file, msg))
+		}
+		return errs
+	}
+	return Errors{lineError(file, strings.TrimPrefix(err.Error(), "yaml: "))}
+}
+
+func lineError(file, msg string) Error {
+	if match := yamlLine.FindStringSubmatch(msg); match != nil {
+		line, _ := strconv.Atoi(match[1])
+		return Error{File: file, Line: line, Msg: match[2]}
+	}
+	return Error{File: file, Msg: msg}
+}
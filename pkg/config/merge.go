@@ -0,0 +1,285 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// ManualMarker in a comment of an update entry keeps the entry as it is, a
+	// generated entry with the same key is dropped in favour of it.
+	ManualMarker = "dependabot-templater: manual"
+	// StaleMarker is added to entries that are no longer generated when they
+	// are marked instead of removed.
+	StaleMarker = "dependabot-templater: stale"
+)
+
+// Stale selects what happens to existing entries that are no longer generated.
+type Stale string
+
+const (
+	StaleRemove Stale = "remove"
+	StaleMark   Stale = "mark"
+)
+
+// MergeReport lists the keys of the update entries changed by a merge.
+type MergeReport struct {
+	Added   []Key
+	Updated []Key
+	Stale   []Key
+	Manual  []Key
+}
+
+type mergeOptions struct {
+	stale      Stale
+	ecosystems []string
+	owned      []string
+}
+
+type MergeOption func(*mergeOptions)
+
+// WithStale selects what happens to entries that are no longer generated,
+// they are removed by default.
+func WithStale(stale Stale) MergeOption {
+	return func(o *mergeOptions) {
+		if stale != "" {
+			o.stale = stale
+		}
+	}
+}
+
+// WithEcosystems limits the stale entries to the given ecosystems, entries of
+// other ecosystems weren't part of the generation and are kept. Without it
+// every entry that is not generated is stale.
+func WithEcosystems(ecosystems ...string) MergeOption {
+	return func(o *mergeOptions) {
+		o.ecosystems = ecosystems
+	}
+}
+
+// WithOwnedKeys lists the keys of an update entry the generator is configured
+// to write. An updated entry loses an owned key the generated entry doesn't
+// have, other keys, like hand-written reviewers, are kept.
+func WithOwnedKeys(keys ...string) MergeOption {
+	return func(o *mergeOptions) {
+		o.owned = keys
+	}
+}
+
+// Merge updates the generated entries of an existing dependabot.yml. Entries
+// are matched by their Key: matching entries are updated, new entries are
+// appended and entries that are no longer generated are removed or marked
+// stale. The generated keys of an updated entry are replaced as a whole, see
+// WithOwnedKeys for the keys that are dropped. Entries marked with
+// ManualMarker, the other keys and comments are kept.
+func Merge(existing []byte, generated *Config, opts ...MergeOption) ([]byte, MergeReport, error) {
+	o := mergeOptions{stale: StaleRemove}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	var report MergeReport
+	var doc yaml.Node
+	if err := yaml.Unmarshal(existing, &doc); err != nil {
+		return nil, report, ParseError("", err)
+	}
+	if len(doc.Content) == 0 {
+		for _, u := range generated.Updates {
+			report.Added = append(report.Added, u.Key())
+		}
+		out, err := generated.Marshal()
+		return out, report, err
+	}
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return nil, report, fmt.Errorf("expected a mapping at line %d", root.Line)
+	}
+
+	var gen yaml.Node
+	if err := gen.Encode(generated); err != nil {
+		return nil, report, err
+	}
+
+	if registries := mappingValue(&gen, "registries"); registries != nil {
+		existingRegistries := mappingValue(root, "registries")
+		if existingRegistries == nil {
+			existingRegistries = &yaml.Node{Kind: yaml.MappingNode}
+			insertBefore(root, "updates", "registries", existingRegistries)
+		}
+		for i := 0; i+1 < len(registries.Content); i += 2 {
+			setMappingValue(existingRegistries, registries.Content[i].Value, registries.Content[i+1])
+		}
+	}
+
+	updates := mappingValue(root, "updates")
+	if updates == nil {
+		updates = &yaml.Node{Kind: yaml.SequenceNode}
+		setMappingValue(root, "updates", updates)
+	}
+	if updates.Kind != yaml.SequenceNode {
+		return nil, report, fmt.Errorf("expected a sequence for updates at line %d", updates.Line)
+	}
+
+	generatedByKey := make(map[Key]*yaml.Node, len(generated.Updates))
+	genUpdates := mappingValue(&gen, "updates")
+	for i, u := range generated.Updates {
+		generatedByKey[u.Key()] = genUpdates.Content[i]
+	}
+
+	merged := make(map[Key]bool, len(generated.Updates))
+	content := make([]*yaml.Node, 0, len(updates.Content)+len(generated.Updates))
+	for _, entry := range updates.Content {
+		var u Update
+		if err := entry.Decode(&u); err != nil {
+			return nil, report, err
+		}
+		key := u.Key()
+		switch node, ok := generatedByKey[key]; {
+		case hasComment(entry, ManualMarker):
+			report.Manual = append(report.Manual, key)
+			merged[key] = true
+		case ok && !merged[key]:
+			mergeEntry(entry, node, o.owned)
+			entry.HeadComment = removeComment(entry.HeadComment, StaleMarker)
+			report.Updated = append(report.Updated, key)
+			merged[key] = true
+		case o.ecosystems != nil && !slices.Contains(o.ecosystems, key.Ecosystem):
+		case o.stale == StaleMark:
+			if !hasComment(entry, StaleMarker) {
+				entry.HeadComment = strings.TrimSpace(entry.HeadComment + "\n# " + StaleMarker)
+			}
+			report.Stale = append(report.Stale, key)
+		default:
+			report.Stale = append(report.Stale, key)
+			continue
+		}
+		content = append(content, entry)
+	}
+	for i, u := range generated.Updates {
+		if key := u.Key(); !merged[key] {
+			content = append(content, genUpdates.Content[i])
+			report.Added = append(report.Added, key)
+			merged[key] = true
+		}
+	}
+	updates.Content = content
+
+	var buf bytes.Buffer
+	if bytes.HasPrefix(bytes.TrimSpace(existing), []byte("---")) {
+		buf.WriteString("---\n")
+	}
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&doc); err != nil {
+		return nil, report, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, report, err
+	}
+	return buf.Bytes(), report, nil
+}
+
+// mergeEntry replaces the keys of dst by the ones of src and drops the owned
+// keys missing in src. The comments of dst are kept.
+func mergeEntry(dst, src *yaml.Node, owned []string) {
+	content := dst.Content[:0]
+	for i := 0; i+1 < len(dst.Content); i += 2 {
+		key := dst.Content[i].Value
+		if slices.Contains(owned, key) && mappingValue(src, key) == nil {
+			continue
+		}
+		content = append(content, dst.Content[i], dst.Content[i+1])
+	}
+	dst.Content = content
+	for i := 0; i+1 < len(src.Content); i += 2 {
+		key, value := src.Content[i].Value, src.Content[i+1]
+		if old := mappingValue(dst, key); old != nil {
+			copyComments(old, value)
+		}
+		setMappingValue(dst, key, value)
+	}
+}
+
+// copyComments moves the comments of the keys and values of old to the same
+// keys of value.
+func copyComments(old, value *yaml.Node) {
+	if old.Kind != yaml.MappingNode || value.Kind != yaml.MappingNode {
+		return
+	}
+	for i := 0; i+1 < len(value.Content); i += 2 {
+		for j := 0; j+1 < len(old.Content); j += 2 {
+			if old.Content[j].Value != value.Content[i].Value {
+				continue
+			}
+			oldKey, oldValue := old.Content[j], old.Content[j+1]
+			key, v := value.Content[i], value.Content[i+1]
+			key.HeadComment, key.LineComment, key.FootComment = oldKey.HeadComment, oldKey.LineComment, oldKey.FootComment
+			v.HeadComment, v.LineComment, v.FootComment = oldValue.HeadComment, oldValue.LineComment, oldValue.FootComment
+			copyComments(oldValue, v)
+		}
+	}
+}
+
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node.Kind == yaml.DocumentNode && len(node.Content) > 0 {
+		node = node.Content[0]
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// setMappingValue replaces the value of key, keeping the comments of the old
+// value, or appends key.
+func setMappingValue(node *yaml.Node, key string, value *yaml.Node) {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			old := node.Content[i+1]
+			value.HeadComment, value.LineComment, value.FootComment = old.HeadComment, old.LineComment, old.FootComment
+			node.Content[i+1] = value
+			return
+		}
+	}
+	node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}, value)
+}
+
+func insertBefore(node *yaml.Node, before, key string, value *yaml.Node) {
+	keyNode := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == before {
+			node.Content = append(node.Content[:i], append([]*yaml.Node{keyNode, value}, node.Content[i:]...)...)
+			return
+		}
+	}
+	node.Content = append(node.Content, keyNode, value)
+}
+
+// hasComment reports whether marker is part of a comment of the entry or of
+// one of its keys and scalar values.
+func hasComment(entry *yaml.Node, marker string) bool {
+	nodes := append([]*yaml.Node{entry}, entry.Content...)
+	for _, n := range nodes {
+		if strings.Contains(n.HeadComment, marker) || strings.Contains(n.LineComment, marker) || strings.Contains(n.FootComment, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+func removeComment(comment, marker string) string {
+	lines := strings.Split(comment, "\n")
+	kept := lines[:0]
+	for _, line := range lines {
+		if !strings.Contains(line, marker) {
+			kept = append(kept, line)
+		}
+	}
+	return strings.Join(kept, "\n")
+}
@@ -0,0 +1,137 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const existing = `---
+# https://docs.github.com/github/administering-a-repository/configuration-options-for-dependency-updates
+version: 2
+updates:
+  # keeps the go toolchain on the version of the base image
+  - package-ecosystem: gomod # dependabot-templater: manual
+    directory: /
+    schedule:
+      interval: daily
+  - package-ecosystem: npm
+    directory: /web
+    schedule:
+      interval: weekly # reviewed with the frontend team
+      day: monday
+    reviewers: [frontend]
+    versioning-strategy: increase
+    groups:
+      minor:
+        patterns: ["*"]
+  - package-ecosystem: docker
+    directory: /legacy
+    schedule:
+      interval: weekly
+# end of updates
+`
+
+func generatedConfig() *Config {
+	cfg := New()
+	cfg.Registries = map[string]Registry{"npm": {Type: "npm-registry", URL: "https://npm.example.com"}}
+	cfg.Updates = append(cfg.Updates,
+		Update{PackageEcosystem: "gomod", Directory: "/", Schedule: Schedule{Interval: "weekly", Day: "sunday"}},
+		Update{PackageEcosystem: "npm", Directory: "/web", Schedule: Schedule{Interval: "monthly"}, Registries: StringList{"npm"}},
+		Update{PackageEcosystem: "pip", Directory: "/tools", Schedule: Schedule{Interval: "weekly", Day: "sunday"}},
+	)
+	return cfg
+}
+
+func TestMerge(t *testing.T) {
+	out, report, err := Merge([]byte(existing), generatedConfig())
+	require.NoError(t, err)
+	assert.Equal(t, `---
+# https://docs.github.com/github/administering-a-repository/configuration-options-for-dependency-updates
+version: 2
+registries:
+  npm:
+    type: npm-registry
+    url: https://npm.example.com
+updates:
+  # keeps the go toolchain on the version of the base image
+  - package-ecosystem: gomod # dependabot-templater: manual
+    directory: /
+    schedule:
+      interval: daily
+  - package-ecosystem: npm
+    directory: /web
+    schedule:
+      interval: monthly # reviewed with the frontend team
+    reviewers: [frontend]
+    versioning-strategy: increase
+    groups:
+      minor:
+        patterns: ["*"]
+    registries:
+      - npm
+  - package-ecosystem: pip
+    directory: /tools
+    schedule:
+      interval: weekly
+      day: sunday
+# end of updates
+`, string(out))
+	assert.Equal(t, MergeReport{
+		Added:   []Key{{Ecosystem: "pip", Directory: "/tools"}},
+		Updated: []Key{{Ecosystem: "npm", Directory: "/web"}},
+		Stale:   []Key{{Ecosystem: "docker", Directory: "/legacy"}},
+		Manual:  []Key{{Ecosystem: "gomod", Directory: "/"}},
+	}, report)
+
+	again, _, err := Merge(out, generatedConfig())
+	require.NoError(t, err)
+	assert.Equal(t, string(out), string(again))
+}
+
+func TestMergeOwnedKeys(t *testing.T) {
+	// The settings configure groups, the hand-written reviewers are kept.
+	out, _, err := Merge([]byte(existing), generatedConfig(), WithOwnedKeys("groups", "labels"))
+	require.NoError(t, err)
+	assert.Contains(t, string(out), "    reviewers: [frontend]\n    versioning-strategy: increase\n    registries:\n")
+	assert.NotContains(t, string(out), "groups:")
+}
+
+func TestMergeEcosystems(t *testing.T) {
+	cfg := New()
+	cfg.Updates = append(cfg.Updates, Update{PackageEcosystem: "pip", Directory: "/tools", Schedule: Schedule{Interval: "weekly"}})
+
+	// Only pip was generated, the entries of the other ecosystems are kept.
+	out, report, err := Merge([]byte(existing), cfg, WithEcosystems("pip"))
+	require.NoError(t, err)
+	assert.Empty(t, report.Stale)
+	assert.Contains(t, string(out), "  - package-ecosystem: npm\n    directory: /web\n")
+	assert.Contains(t, string(out), "  - package-ecosystem: docker\n    directory: /legacy\n")
+
+	_, report, err = Merge([]byte(existing), cfg, WithEcosystems("pip", "docker"))
+	require.NoError(t, err)
+	assert.Equal(t, []Key{{Ecosystem: "docker", Directory: "/legacy"}}, report.Stale)
+}
+
+func TestMergeMarkStale(t *testing.T) {
+	out, _, err := Merge([]byte(existing), generatedConfig(), WithStale(StaleMark))
+	require.NoError(t, err)
+	assert.Contains(t, string(out), "  # dependabot-templater: stale\n  - package-ecosystem: docker\n    directory: /legacy\n")
+
+	cfg := generatedConfig()
+	cfg.Updates = append(cfg.Updates, Update{PackageEcosystem: "docker", Directory: "/legacy", Schedule: Schedule{Interval: "daily"}})
+	out, _, err = Merge(out, cfg, WithStale(StaleMark))
+	require.NoError(t, err)
+	assert.NotContains(t, string(out), StaleMarker)
+	assert.Contains(t, string(out), "  - package-ecosystem: docker\n    directory: /legacy\n    schedule:\n      interval: daily\n")
+}
+
+func TestMergeEmpty(t *testing.T) {
+	out, report, err := Merge(nil, generatedConfig())
+	require.NoError(t, err)
+	expected, err := generatedConfig().Marshal()
+	require.NoError(t, err)
+	assert.Equal(t, string(expected), string(out))
+	assert.Len(t, report.Added, 3)
+}
@@ -0,0 +1,109 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	// The embedded database makes ValidateTimezone independent of the zoneinfo
+	// of the host, e.g. on scratch images or Windows.
+	_ "time/tzdata"
+)
+
+// cronFields are the fields of a cronjob with their bounds and names.
+var cronFields = []struct {
+	name        string
+	first, last int
+	names       []string
+}{
+	{name: "minute", first: 0, last: 59},
+	{name: "hour", first: 0, last: 23},
+	{name: "day of month", first: 1, last: 31},
+	{name: "month", first: 1, last: 12, names: []string{"jan", "feb", "mar", "apr", "may", "jun", "jul", "aug", "sep", "oct", "nov", "dec"}},
+	// Sunday is 0 and 7.
+	{name: "day of week", first: 0, last: 7, names: []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}},
+}
+
+// ValidateTime checks a schedule time formatted as hh:mm.
+func ValidateTime(s string) error {
+	if !timeOfDay.MatchString(s) {
+		return fmt.Errorf("time %q must be formatted as hh:mm", s)
+	}
+	return nil
+}
+
+// ValidateTimezone checks an IANA time zone name like Europe/Berlin.
+func ValidateTimezone(s string) error {
+	// LoadLocation accepts "Local", which depends on the machine.
+	if s == "" || s == "Local" {
+		return fmt.Errorf("unknown time zone %q, expected an IANA name like Europe/Berlin", s)
+	}
+	if _, err := time.LoadLocation(s); err != nil {
+		return fmt.Errorf("unknown time zone %q, expected an IANA name like Europe/Berlin", s)
+	}
+	return nil
+}
+
+// ValidateCron checks a cronjob with the five fields minute, hour, day of
+// month, month and day of week. Fields are lists of values, ranges and
+// steps like "1-5", "*/15" or "mon,wed".
+func ValidateCron(s string) error {
+	fields := strings.Fields(s)
+	if len(fields) != len(cronFields) {
+		return fmt.Errorf("cronjob %q must have %d fields, minute hour day-of-month month day-of-week", s, len(cronFields))
+	}
+	for i, field := range fields {
+		f := cronFields[i]
+		for _, item := range strings.Split(field, ",") {
+			if err := cronItem(item, f.first, f.last, f.names); err != nil {
+				return fmt.Errorf("cronjob %q: invalid %s %q: %w", s, f.name, item, err)
+			}
+		}
+	}
+	return nil
+}
+
+func cronItem(item string, first, last int, names []string) error {
+	item, step, hasStep := strings.Cut(item, "/")
+	if hasStep {
+		if n, err := strconv.Atoi(step); err != nil || n < 1 {
+			return errors.New("step must be a positive number")
+		}
+	}
+	if item == "*" {
+		return nil
+	}
+	from, to, isRange := strings.Cut(item, "-")
+	lo, err := cronValue(from, first, last, names)
+	if err != nil {
+		return err
+	}
+	if !isRange {
+		if hasStep {
+			return errors.New("step requires * or a range")
+		}
+		return nil
+	}
+	hi, err := cronValue(to, first, last, names)
+	if err != nil {
+		return err
+	}
+	if lo > hi {
+		return errors.New("range start is after its end")
+	}
+	return nil
+}
+
+func cronValue(s string, first, last int, names []string) (int, error) {
+	for i, name := range names {
+		if strings.EqualFold(s, name) {
+			return i + first, nil
+		}
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil || n < first || n > last {
+		return 0, fmt.Errorf("expected a value from %d to %d", first, last)
+	}
+	return n, nil
+}
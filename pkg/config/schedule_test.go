@@ -0,0 +1,63 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateCron(t *testing.T) {
+	for _, expr := range []string{"0 9 * * *", "*/15 8-18 * * mon-fri", "30 6 1,15 jan-jun 0", "0 0 * * 7", "0 22 * * SUN"} {
+		assert.NoError(t, ValidateCron(expr), expr)
+	}
+	for expr, msg := range map[string]string{
+		"0 9 * *":         `cronjob "0 9 * *" must have 5 fields, minute hour day-of-month month day-of-week`,
+		"60 9 * * *":      `cronjob "60 9 * * *": invalid minute "60": expected a value from 0 to 59`,
+		"0 9 0 * *":       `cronjob "0 9 0 * *": invalid day of month "0": expected a value from 1 to 31`,
+		"0 9 * * fri-mon": `cronjob "0 9 * * fri-mon": invalid day of week "fri-mon": range start is after its end`,
+		"*/0 9 * * *":     `cronjob "*/0 9 * * *": invalid minute "*/0": step must be a positive number`,
+		"5/10 9 * * *":    `cronjob "5/10 9 * * *": invalid minute "5/10": step requires * or a range`,
+	} {
+		assert.EqualError(t, ValidateCron(expr), msg, expr)
+	}
+}
+
+func TestValidateTimezone(t *testing.T) {
+	assert.NoError(t, ValidateTimezone("Europe/Berlin"))
+	assert.NoError(t, ValidateTimezone("UTC"))
+	assert.EqualError(t, ValidateTimezone("Mars/Olympus"), `unknown time zone "Mars/Olympus", expected an IANA name like Europe/Berlin`)
+	assert.Error(t, ValidateTimezone("Local"))
+}
+
+func TestValidateSchedule(t *testing.T) {
+	err := ValidateFile("dependabot.yml", []byte(`version: 2
+updates:
+  - package-ecosystem: npm
+    directory: /
+    schedule:
+      interval: cron
+      time: "09:00"
+      timezone: Europe/Nowhere
+  - package-ecosystem: gomod
+    directory: /
+    schedule:
+      interval: daily
+      cronjob: "0 9 * * *"
+  - package-ecosystem: cargo
+    directory: /
+    schedule:
+      interval: cron
+      cronjob: "0 25 * * *"
+      timezone: America/New_York
+`))
+	var errs Errors
+	require.ErrorAs(t, err, &errs)
+	assert.Equal(t, []string{
+		`dependabot.yml:7: updates[0].schedule.time: time is not supported for the cron interval, set it in the cronjob`,
+		`dependabot.yml:8: updates[0].schedule.timezone: unknown time zone "Europe/Nowhere", expected an IANA name like Europe/Berlin`,
+		`dependabot.yml:6: updates[0].schedule.cronjob: cronjob is required for the cron interval`,
+		`dependabot.yml:13: updates[1].schedule.cronjob: cronjob is only supported for the cron interval`,
+		`dependabot.yml:18: updates[2].schedule.cronjob: cronjob "0 25 * * *": invalid hour "25": expected a value from 0 to 23`,
+	}, messages(errs))
+}
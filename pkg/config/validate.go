@@ -0,0 +1,417 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"slices"
+
+	"github.com/containifyci/dependabot-templater/internal/yamlpath"
+
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	// Ecosystems are the values of package-ecosystem supported by Dependabot.
+	Ecosystems = []string{
+		"bun", "bundler", "cargo", "composer", "devcontainers", "docker", "docker-compose",
+		"dotnet-sdk", "elm", "github-actions", "gitsubmodule", "gomod", "gradle", "helm",
+		"maven", "mix", "npm", "nuget", "pip", "pub", "swift", "terraform", "uv",
+	}
+	Intervals = []string{"daily", "weekly", "monthly", "quarterly", "semiannually", "yearly", "cron"}
+	Days      = []string{"monday", "tuesday", "wednesday", "thursday", "friday", "saturday", "sunday"}
+	// IgnoreUpdateTypes are the update-types of an ignore rule.
+	IgnoreUpdateTypes = []string{"version-update:semver-major", "version-update:semver-minor", "version-update:semver-patch"}
+	// AllowDependencyTypes are the dependency-types of an allow rule.
+	AllowDependencyTypes = []string{"direct", "indirect", "all", "production", "development"}
+	// GroupUpdateTypes are the update-types of a group.
+	GroupUpdateTypes = []string{"major", "minor", "patch"}
+	RebaseStrategies = []string{"auto", "disabled"}
+	// CooldownEcosystems support cooldown, SemverCooldownEcosystems also the
+	// semver-*-days.
+	CooldownEcosystems = []string{
+		"bun", "bundler", "cargo", "composer", "devcontainers", "docker", "docker-compose",
+		"dotnet-sdk", "elm", "github-actions", "gomod", "gradle", "helm", "maven", "mix",
+		"npm", "nuget", "pip", "pub", "swift", "terraform", "uv",
+	}
+	SemverCooldownEcosystems = []string{
+		"bun", "bundler", "cargo", "composer", "gomod", "gradle", "maven", "mix", "npm",
+		"nuget", "pip", "pub", "uv",
+	}
+	// BranchSeparators are the separators of pull-request-branch-name.
+	BranchSeparators = []string{"-", "_", "/"}
+)
+
+// RegistryTypes maps the Dependabot registry types to the package ecosystems
+// they can be used with. A nil list means every ecosystem.
+var RegistryTypes = map[string][]string{
+	"cargo-registry":      {"cargo"},
+	"composer-repository": {"composer"},
+	"docker-registry":     {"docker", "docker-compose", "helm"},
+	"git":                 nil,
+	"goproxy-server":      {"gomod"},
+	"helm-registry":       {"helm"},
+	"hex-organization":    {"mix"},
+	"hex-repository":      {"mix"},
+	"maven-repository":    {"maven", "gradle"},
+	"npm-registry":        {"npm", "bun"},
+	"nuget-feed":          {"nuget"},
+	"pub-repository":      {"pub"},
+	"python-index":        {"pip", "uv"},
+	"rubygems-server":     {"bundler"},
+	"terraform-registry":  {"terraform"},
+}
+
+var (
+	timeOfDay = regexp.MustCompile(`^([01][0-9]|2[0-3]):[0-5][0-9]$`)
+	// groupName follows the rules Dependabot applies to group names.
+	groupName = regexp.MustCompile(`^[A-Za-z0-9]([A-Za-z0-9_|-]*[A-Za-z0-9])?$`)
+	// registryName keeps the names plain YAML scalars, they are written
+	// unquoted by the templates.
+	registryName = regexp.MustCompile(`^[A-Za-z0-9]([A-Za-z0-9._-]*[A-Za-z0-9])?$`)
+)
+
+// ValidateRegistryName checks that name only has letters, digits, dots,
+// underscores and hyphens.
+func ValidateRegistryName(name string) error {
+	if !registryName.MatchString(name) {
+		return fmt.Errorf("invalid registry name %q, only letters, digits, dots, underscores and hyphens are allowed", name)
+	}
+	return nil
+}
+
+// Validate checks the config against the rules of the version 2 schema.
+// Problems are reported as Errors with the path of the offending value.
+func (c *Config) Validate() error {
+	return c.validate("", nil).errOrNil()
+}
+
+// ValidateFile parses and validates a dependabot.yml, problems are reported as
+// Errors with their line.
+func ValidateFile(file string, data []byte) error {
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return ParseError(file, err)
+	}
+	c := &Config{}
+	if err := yaml.NewDecoder(bytes.NewReader(data)).Decode(c); err != nil && !errors.Is(err, io.EOF) {
+		return ParseError(file, err)
+	}
+	return c.validate(file, &root).errOrNil()
+}
+
+type validator struct {
+	file string
+	root *yaml.Node
+	errs Errors
+}
+
+// add records a problem of the value at path, path elements are mapping keys
+// or sequence indexes.
+func (v *validator) add(path []any, format string, args ...any) {
+	v.errs = append(v.errs, Error{
+		File: v.file,
+		Line: yamlpath.Line(v.root, path),
+		Path: yamlpath.Format(path),
+		Msg:  fmt.Sprintf(format, args...),
+	})
+}
+
+func (v *validator) errOrNil() error {
+	if len(v.errs) == 0 {
+		return nil
+	}
+	return v.errs
+}
+
+func (c *Config) validate(file string, root *yaml.Node) *validator {
+	v := &validator{file: file, root: root}
+	if c.Version != 2 {
+		v.add([]any{"version"}, "version must be 2")
+	}
+	for _, name := range sortedKeys(c.Registries) {
+		if err := ValidateRegistryName(name); err != nil {
+			v.add([]any{"registries", name}, "%s", err)
+		}
+		v.registry([]any{"registries", name}, c.Registries[name])
+	}
+	if c.Updates == nil {
+		v.add([]any{"updates"}, "updates is required")
+	}
+	seen := make(map[Key]int, len(c.Updates))
+	for i, u := range c.Updates {
+		path := []any{"updates", i}
+		v.update(path, c, u)
+		key := u.Key()
+		if first, ok := seen[key]; ok {
+			v.add(path, "duplicate update for %s, already defined by updates[%d]", key, first)
+			continue
+		}
+		seen[key] = i
+	}
+	return v
+}
+
+func (v *validator) registry(path []any, r Registry) {
+	if _, ok := RegistryTypes[r.Type]; !ok {
+		v.add(append(path, "type"), "unknown registry type %q", r.Type)
+	}
+	switch r.Type {
+	case "hex-organization":
+		if r.Organization == "" {
+			v.add(path, "organization is required for %s", r.Type)
+		}
+	default:
+		if r.URL == "" {
+			v.add(path, "url is required for %s", r.Type)
+		}
+	}
+}
+
+func (v *validator) update(path []any, c *Config, u Update) {
+	if !slices.Contains(Ecosystems, u.PackageEcosystem) {
+		v.add(append(path, "package-ecosystem"), "unknown package-ecosystem %q", u.PackageEcosystem)
+	}
+	switch {
+	case u.Directory == "" && len(u.Directories) == 0:
+		v.add(path, "directory or directories is required")
+	case u.Directory != "" && len(u.Directories) > 0:
+		v.add(path, "directory and directories are mutually exclusive")
+	}
+	v.schedule(append(path, "schedule"), u.Schedule)
+
+	if u.RebaseStrategy != "" && !slices.Contains(RebaseStrategies, u.RebaseStrategy) {
+		v.add(append(path, "rebase-strategy"), "unknown rebase-strategy %q, expected one of %v", u.RebaseStrategy, RebaseStrategies)
+	}
+	if u.OpenPullRequestsLimit != nil && *u.OpenPullRequestsLimit < 0 {
+		v.add(append(path, "open-pull-requests-limit"), "open-pull-requests-limit must not be negative")
+	}
+	if u.CommitMessage != nil && u.CommitMessage.Include != "" && u.CommitMessage.Include != "scope" {
+		v.add(append(path, "commit-message", "include"), "include only supports scope")
+	}
+	if u.PullRequestBranchName != nil && !slices.Contains(BranchSeparators, u.PullRequestBranchName.Separator) {
+		v.add(append(path, "pull-request-branch-name", "separator"), "unknown separator %q, expected one of %v", u.PullRequestBranchName.Separator, BranchSeparators)
+	}
+	if u.InsecureExternalCodeExecution != "" && u.InsecureExternalCodeExecution != "allow" && u.InsecureExternalCodeExecution != "deny" {
+		v.add(append(path, "insecure-external-code-execution"), "expected allow or deny")
+	}
+
+	for i, name := range u.Registries {
+		if name == "*" {
+			continue
+		}
+		r, ok := c.Registries[name]
+		if !ok {
+			v.add(append(path, "registries", i), "registry %q is not defined in registries", name)
+			continue
+		}
+		if ecosystems, known := RegistryTypes[r.Type]; known && ecosystems != nil && !slices.Contains(ecosystems, u.PackageEcosystem) {
+			v.add(append(path, "registries", i), "registry %q of type %s can't be used with %s", name, r.Type, u.PackageEcosystem)
+		}
+	}
+	for _, name := range sortedKeys(u.Groups) {
+		v.group(append(path, "groups", name), name, u.Groups[name])
+	}
+	for i, ignore := range u.Ignore {
+		v.problems(append(path, "ignore", i), ignore.Problems(u.PackageEcosystem))
+	}
+	for i, allow := range u.Allow {
+		v.problems(append(path, "allow", i), allow.Problems())
+	}
+	if u.Cooldown != nil {
+		v.problems(append(path, "cooldown"), u.Cooldown.Problems(u.PackageEcosystem))
+	}
+}
+
+func (v *validator) schedule(path []any, s Schedule) {
+	switch {
+	case s.Interval == "":
+		v.add(append(path, "interval"), "interval is required")
+	case !slices.Contains(Intervals, s.Interval):
+		v.add(append(path, "interval"), "unknown interval %q, expected one of %v", s.Interval, Intervals)
+	}
+	if s.Day != "" {
+		if !slices.Contains(Days, s.Day) {
+			v.add(append(path, "day"), "unknown day %q, expected one of %v", s.Day, Days)
+		} else if s.Interval != "weekly" {
+			v.add(append(path, "day"), "day is only supported for the weekly interval")
+		}
+	}
+	if s.Time != "" {
+		if err := ValidateTime(s.Time); err != nil {
+			v.add(append(path, "time"), "%s", err)
+		} else if s.Interval == "cron" {
+			v.add(append(path, "time"), "time is not supported for the cron interval, set it in the cronjob")
+		}
+	}
+	if s.Timezone != "" {
+		if err := ValidateTimezone(s.Timezone); err != nil {
+			v.add(append(path, "timezone"), "%s", err)
+		}
+	}
+	switch {
+	case s.Interval == "cron" && s.Cronjob == "":
+		v.add(append(path, "cronjob"), "cronjob is required for the cron interval")
+	case s.Cronjob == "":
+	case s.Interval != "cron":
+		v.add(append(path, "cronjob"), "cronjob is only supported for the cron interval")
+	default:
+		if err := ValidateCron(s.Cronjob); err != nil {
+			v.add(append(path, "cronjob"), "%s", err)
+		}
+	}
+}
+
+func (v *validator) group(path []any, name string, g Group) {
+	v.problems(path, g.Problems(name))
+}
+
+func (v *validator) problems(path []any, problems []Problem) {
+	for _, p := range problems {
+		v.add(append(path, p.Path...), "%s", p.Msg)
+	}
+}
+
+// Problem is a problem of a value, Path is relative to the checked value.
+type Problem struct {
+	Path []any
+	Msg  string
+}
+
+func problem(path []any, format string, args ...any) Problem {
+	return Problem{Path: path, Msg: fmt.Sprintf(format, args...)}
+}
+
+// Problems checks the group called name.
+func (g Group) Problems(name string) []Problem {
+	var problems []Problem
+	if !groupName.MatchString(name) {
+		problems = append(problems, problem(nil, "invalid group name %q, only letters, digits, pipes, underscores and hyphens are allowed", name))
+	}
+	if g.AppliesTo != "" && g.AppliesTo != "version-updates" && g.AppliesTo != "security-updates" {
+		problems = append(problems, problem([]any{"applies-to"}, "expected version-updates or security-updates"))
+	}
+	if g.DependencyType != "" && g.DependencyType != "development" && g.DependencyType != "production" {
+		problems = append(problems, problem([]any{"dependency-type"}, "expected development or production"))
+	}
+	if len(g.Patterns) == 0 && len(g.ExcludePatterns) == 0 && g.DependencyType == "" && len(g.UpdateTypes) == 0 {
+		problems = append(problems, problem(nil, "group needs at least one of patterns, exclude-patterns, dependency-type or update-types"))
+	}
+	for i, t := range g.UpdateTypes {
+		if !slices.Contains(GroupUpdateTypes, t) {
+			problems = append(problems, problem([]any{"update-types", i}, "unknown update-type %q, expected one of %v", t, GroupUpdateTypes))
+		}
+	}
+	return problems
+}
+
+// Problems checks the ignore rule, the versions are checked with the range
+// syntax of the ecosystem unless it is empty.
+func (i Ignore) Problems(ecosystem string) []Problem {
+	var problems []Problem
+	if i.DependencyName == "" {
+		problems = append(problems, problem(nil, "dependency-name is required"))
+	}
+	if ecosystem != "" {
+		for j, r := range i.Versions {
+			if err := ValidateVersionRange(ecosystem, r); err != nil {
+				problems = append(problems, problem([]any{"versions", j}, "%s", err))
+			}
+		}
+	}
+	for j, t := range i.UpdateTypes {
+		if !slices.Contains(IgnoreUpdateTypes, t) {
+			problems = append(problems, problem([]any{"update-types", j}, "unknown update-type %q, expected one of %v", t, IgnoreUpdateTypes))
+		}
+	}
+	return problems
+}
+
+// Problems checks the allow rule.
+func (a Allow) Problems() []Problem {
+	var problems []Problem
+	if a.DependencyName == "" && a.DependencyType == "" {
+		problems = append(problems, problem(nil, "dependency-name or dependency-type is required"))
+	}
+	if a.DependencyType != "" && !slices.Contains(AllowDependencyTypes, a.DependencyType) {
+		problems = append(problems, problem([]any{"dependency-type"}, "unknown dependency-type %q, expected one of %v", a.DependencyType, AllowDependencyTypes))
+	}
+	return problems
+}
+
+// maxCooldownDays is the longest cooldown Dependabot accepts.
+const maxCooldownDays = 90
+
+// Problems checks the cooldown, the support of the ecosystem and the presence
+// of days are checked unless it is empty. Settings only set some keys of the
+// cooldown of a less specific level.
+func (c Cooldown) Problems(ecosystem string) []Problem {
+	if ecosystem != "" {
+		if problems := c.Unsupported(ecosystem); len(problems) > 0 {
+			return problems
+		}
+	}
+	var problems []Problem
+	for _, days := range c.days() {
+		if days.value != nil && (*days.value < 0 || *days.value > maxCooldownDays) {
+			problems = append(problems, problem([]any{days.key}, "%s must be between 0 and %d", days.key, maxCooldownDays))
+		}
+	}
+	if ecosystem != "" && c.DefaultDays == nil && c.SemverMajorDays == nil && c.SemverMinorDays == nil && c.SemverPatchDays == nil {
+		problems = append(problems, problem(nil, "cooldown needs at least one of default-days or semver-major-days, semver-minor-days, semver-patch-days"))
+	}
+	return problems
+}
+
+// Unsupported reports a cooldown of an ecosystem without support and the
+// semver days other than 0 of an ecosystem without semantic versions.
+func (c Cooldown) Unsupported(ecosystem string) []Problem {
+	if !slices.Contains(CooldownEcosystems, ecosystem) {
+		return []Problem{problem(nil, "cooldown is not supported for %s", ecosystem)}
+	}
+	if slices.Contains(SemverCooldownEcosystems, ecosystem) {
+		return nil
+	}
+	var problems []Problem
+	for _, days := range c.days()[1:] {
+		if days.value != nil && *days.value != 0 {
+			problems = append(problems, problem([]any{days.key}, "%s is not supported for %s, use default-days", days.key, ecosystem))
+		}
+	}
+	return problems
+}
+
+type cooldownDays struct {
+	key   string
+	value *int
+}
+
+// days returns the days of the cooldown, default-days first.
+func (c Cooldown) days() []cooldownDays {
+	return []cooldownDays{
+		{"default-days", c.DefaultDays},
+		{"semver-major-days", c.SemverMajorDays},
+		{"semver-minor-days", c.SemverMinorDays},
+		{"semver-patch-days", c.SemverPatchDays},
+	}
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+// String renders the key for messages.
+func (k Key) String() string {
+	s := fmt.Sprintf("%s in %q", k.Ecosystem, k.Directory)
+	if k.TargetBranch != "" {
+		s += " on " + k.TargetBranch
+	}
+	return s
+}
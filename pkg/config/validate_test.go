@@ -0,0 +1,112 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateFile(t *testing.T) {
+	err := ValidateFile("dependabot.yml", []byte(`version: 2
+registries:
+  npm:
+    type: npm-registry
+    token: secret
+updates:
+  - package-ecosystem: npm
+    directory: /
+    schedule:
+      interval: monthly
+      day: monday
+      time: "9:00"
+    registries: [npm, missing]
+    groups:
+      "minor updates":
+        update-types: [minor, feature]
+    ignore:
+      - dependency-name: "*"
+        update-types: ["version-update:semver-minor", "minor"]
+  - package-ecosystem: npm
+    directory: /
+    schedule:
+      interval: weekly
+  - package-ecosystem: golang
+    schedule:
+      interval: hourly
+`))
+	var errs Errors
+	require.ErrorAs(t, err, &errs)
+	assert.Equal(t, []string{
+		`dependabot.yml:4: registries.npm: url is required for npm-registry`,
+		`dependabot.yml:11: updates[0].schedule.day: day is only supported for the weekly interval`,
+		`dependabot.yml:12: updates[0].schedule.time: time "9:00" must be formatted as hh:mm`,
+		`dependabot.yml:13: updates[0].registries[1]: registry "missing" is not defined in registries`,
+		`dependabot.yml:16: updates[0].groups.minor updates: invalid group name "minor updates", only letters, digits, pipes, underscores and hyphens are allowed`,
+		`dependabot.yml:16: updates[0].groups.minor updates.update-types[1]: unknown update-type "feature", expected one of [major minor patch]`,
+		`dependabot.yml:19: updates[0].ignore[0].update-types[1]: unknown update-type "minor", expected one of [version-update:semver-major version-update:semver-minor version-update:semver-patch]`,
+		`dependabot.yml:20: updates[1]: duplicate update for npm in "/", already defined by updates[0]`,
+		`dependabot.yml:24: updates[2].package-ecosystem: unknown package-ecosystem "golang"`,
+		`dependabot.yml:24: updates[2]: directory or directories is required`,
+		`dependabot.yml:26: updates[2].schedule.interval: unknown interval "hourly", expected one of [daily weekly monthly quarterly semiannually yearly cron]`,
+	}, messages(errs))
+}
+
+func TestValidate(t *testing.T) {
+	cfg := New()
+	cfg.Updates = append(cfg.Updates,
+		Update{PackageEcosystem: "gomod", Directory: "/", Schedule: Schedule{Interval: "weekly"}},
+		Update{PackageEcosystem: "gomod", Directory: "/", Schedule: Schedule{Interval: "weekly"}, TargetBranch: "develop"},
+	)
+	assert.NoError(t, cfg.Validate())
+
+	cfg.Updates[1].TargetBranch = ""
+	assert.EqualError(t, cfg.Validate(), `updates[1]: duplicate update for gomod in "/", already defined by updates[0]`)
+
+	err := ValidateFile("broken.yml", []byte("version: [2"))
+	assert.ErrorContains(t, err, "broken.yml:1:")
+}
+
+func messages(errs Errors) []string {
+	msgs := make([]string, len(errs))
+	for i, err := range errs {
+		msgs[i] = err.Error()
+	}
+	return msgs
+}
+
+func TestValidateCooldown(t *testing.T) {
+	err := ValidateFile("dependabot.yml", []byte(`version: 2
+updates:
+  - package-ecosystem: npm
+    directory: /
+    schedule:
+      interval: weekly
+    cooldown:
+      default-days: 3
+      semver-major-days: 30
+      include: ["*"]
+  - package-ecosystem: docker
+    directory: /
+    schedule:
+      interval: weekly
+    cooldown:
+      default-days: 3
+      semver-patch-days: 1
+  - package-ecosystem: gitsubmodule
+    directory: /
+    schedule:
+      interval: weekly
+    cooldown:
+      default-days: 3
+  - package-ecosystem: pip
+    directory: /
+    schedule:
+      interval: weekly
+    cooldown:
+      semver-minor-days: -1
+`))
+	assert.EqualError(t, err, `dependabot.yml:17: updates[1].cooldown.semver-patch-days: semver-patch-days is not supported for docker, use default-days
+dependabot.yml:23: updates[2].cooldown: cooldown is not supported for gitsubmodule
+dependabot.yml:29: updates[3].cooldown.semver-minor-days: semver-minor-days must be between 0 and 90`)
+}
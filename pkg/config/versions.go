@@ -0,0 +1,94 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var (
+	// version is a version with optional wildcards and pre-release or build
+	// suffix, like 1.2.3, v2, 1.x or 1.0.0-rc.1.
+	version = `v?(\*|[0-9]+(\.([0-9]+|[xX*]))*([-+][0-9A-Za-z.-]+)?)`
+
+	semverComparator = regexp.MustCompile(`^(\^|~|>=|<=|>|<|=)?` + version + `$`)
+	rubyRequirement  = regexp.MustCompile(`^(~>|>=|<=|!=|>|<|=)?\s*` + version + `$`)
+	pep440Specifier  = regexp.MustCompile(`^(~=|===|==|!=|>=|<=|>|<)?\s*` + version + `$`)
+	mavenRange       = regexp.MustCompile(`^[\[(]\s*(` + version + `)?\s*(,\s*(` + version + `)?\s*)?[\])]$`)
+)
+
+// versionSyntax names the version range syntax of the ecosystems in errors.
+var versionSyntax = map[string]string{
+	"npm":      "a semver range like ^1.2.0, >=1.0.0 <2.0.0 or 1.x || 2.x",
+	"bun":      "a semver range like ^1.2.0, >=1.0.0 <2.0.0 or 1.x || 2.x",
+	"composer": "a composer constraint like ^1.2, >=1.0 <2.0 or ^1.0 || ^2.0",
+	"cargo":    "a cargo requirement like ^1.2, >=1.0, <2.0 or 1.*",
+	"pip":      "a PEP 440 specifier like >=1.0,<2.0, ~=1.4 or ==1.*",
+	"uv":       "a PEP 440 specifier like >=1.0,<2.0, ~=1.4 or ==1.*",
+	"maven":    "a maven range like [1.0,2.0), [1.5,) or a requirement like >= 1.0",
+	"gradle":   "a maven range like [1.0,2.0), [1.5,) or a requirement like >= 1.0",
+	"nuget":    "a NuGet range like [1.0,2.0), [1.5,) or a requirement like >= 1.0",
+}
+
+// ValidateVersionRange checks the versions of an ignore rule with the range
+// syntax of the ecosystem's package manager. Other ecosystems use Ruby style
+// requirements like ">= 1.0, < 2" or "~> 1.2".
+func ValidateVersionRange(ecosystem, r string) error {
+	if strings.TrimSpace(r) != "" && validRange(ecosystem, strings.TrimSpace(r)) {
+		return nil
+	}
+	syntax, ok := versionSyntax[ecosystem]
+	if !ok {
+		syntax = "a requirement like >= 1.0, < 2 or ~> 1.2"
+	}
+	return fmt.Errorf("invalid version range %q, expected %s", r, syntax)
+}
+
+func validRange(ecosystem, r string) bool {
+	switch ecosystem {
+	case "npm", "bun", "composer":
+		for _, set := range strings.Split(r, "||") {
+			if !validSemverSet(strings.TrimSpace(set)) {
+				return false
+			}
+		}
+		return true
+	case "cargo":
+		return all(strings.Split(r, ","), semverComparator)
+	case "pip", "uv":
+		return all(strings.Split(r, ","), pep440Specifier)
+	case "maven", "gradle", "nuget":
+		return mavenRange.MatchString(r) || all(strings.Split(r, ","), rubyRequirement)
+	default:
+		return all(strings.Split(r, ","), rubyRequirement)
+	}
+}
+
+// validSemverSet checks a space separated list of comparators or a hyphen
+// range like "1.2.3 - 2.0.0".
+func validSemverSet(set string) bool {
+	if from, to, ok := strings.Cut(set, " - "); ok {
+		return semverComparator.MatchString(strings.TrimSpace(from)) && semverComparator.MatchString(strings.TrimSpace(to))
+	}
+	// Operators may be separated from the version, e.g. ">= 1.0".
+	fields := strings.Fields(set)
+	var comparators []string
+	for i := 0; i < len(fields); i++ {
+		field := fields[i]
+		if strings.Trim(field, "^~<>=") == "" && i+1 < len(fields) {
+			field += fields[i+1]
+			i++
+		}
+		comparators = append(comparators, field)
+	}
+	return len(comparators) > 0 && all(comparators, semverComparator)
+}
+
+func all(parts []string, re *regexp.Regexp) bool {
+	for _, part := range parts {
+		if !re.MatchString(strings.TrimSpace(part)) {
+			return false
+		}
+	}
+	return len(parts) > 0
+}
@@ -0,0 +1,59 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateVersionRange(t *testing.T) {
+	for ecosystem, ranges := range map[string][]string{
+		"npm":            {"^1.2.0", ">=1.0.0 <2.0.0", ">= 1.0.0 < 2", "1.x || 2.x", "1.2.3 - 2.0.0", "~1.2", "*", "4.0.0-beta.1"},
+		"composer":       {"^1.0 || ^2.0", ">=1.0 <2.0", "~1.2"},
+		"cargo":          {"^1.2", ">=1.0, <2.0", "1.*"},
+		"pip":            {">=1.0,<2.0", "~=1.4", "==1.*", "!=1.2"},
+		"maven":          {"[1.0,2.0)", "[1.5,)", "(,1.0]", "[1.2]", ">= 1.0"},
+		"nuget":          {"[1.0, 2.0)"},
+		"bundler":        {"~> 2.3", ">= 1.0, < 2"},
+		"gomod":          {">= 1.22", "v1.2.3"},
+		"github-actions": {">= 4"},
+		"docker":         {"1.x", ">= 3.12"},
+	} {
+		for _, r := range ranges {
+			assert.NoError(t, ValidateVersionRange(ecosystem, r), "%s %s", ecosystem, r)
+		}
+	}
+	for ecosystem, ranges := range map[string][]string{
+		"npm":     {"", ">>1", "1.2.3 -", "latest"},
+		"pip":     {"~>1.4", "^1.0"},
+		"maven":   {"[1.0,2.0", "1.0,2.0)"},
+		"bundler": {"^1.0", ">= 1.0 < 2"},
+	} {
+		for _, r := range ranges {
+			assert.Error(t, ValidateVersionRange(ecosystem, r), "%s %s", ecosystem, r)
+		}
+	}
+	assert.EqualError(t, ValidateVersionRange("pip", "^1.0"), `invalid version range "^1.0", expected a PEP 440 specifier like >=1.0,<2.0, ~=1.4 or ==1.*`)
+	assert.EqualError(t, ValidateVersionRange("gomod", "^1.0"), `invalid version range "^1.0", expected a requirement like >= 1.0, < 2 or ~> 1.2`)
+}
+
+func TestValidateRules(t *testing.T) {
+	err := ValidateFile("dependabot.yml", []byte(`version: 2
+updates:
+  - package-ecosystem: pip
+    directory: /
+    schedule:
+      interval: weekly
+    ignore:
+      - dependency-name: django
+        versions: [">=5.0,<6", "^5"]
+      - versions: ["1.x"]
+    allow:
+      - dependency-type: everything
+      - {}
+`))
+	assert.EqualError(t, err, `dependabot.yml:9: updates[0].ignore[0].versions[1]: invalid version range "^5", expected a PEP 440 specifier like >=1.0,<2.0, ~=1.4 or ==1.*
+dependabot.yml:10: updates[0].ignore[1]: dependency-name is required
+dependabot.yml:12: updates[0].allow[0].dependency-type: unknown dependency-type "everything", expected one of [direct indirect all production development]
+dependabot.yml:13: updates[0].allow[1]: dependency-name or dependency-type is required`)
+}
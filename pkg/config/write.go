@@ -0,0 +1,65 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// WriteFile replaces the file at path with data through a temporary file and
+// a rename, so readers never see a partially written config. The file is only
+// touched if its content changes, changed reports whether it did.
+func WriteFile(path string, data []byte) (changed bool, err error) {
+	mode := fs.FileMode(0o644)
+	current, err := os.ReadFile(path)
+	switch {
+	case err == nil:
+		if bytes.Equal(current, data) {
+			return false, nil
+		}
+		if info, err := os.Stat(path); err == nil {
+			mode = info.Mode().Perm()
+		}
+	case errors.Is(err, fs.ErrNotExist):
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			return false, err
+		}
+	default:
+		return false, err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return false, err
+	}
+	defer func() {
+		if err != nil {
+			os.Remove(tmp.Name())
+		}
+	}()
+	if _, err = tmp.Write(data); err != nil {
+		tmp.Close()
+		return false, err
+	}
+	if err = tmp.Close(); err != nil {
+		return false, err
+	}
+	if err = os.Chmod(tmp.Name(), mode); err != nil {
+		return false, err
+	}
+	if err = os.Rename(tmp.Name(), path); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Target returns the dependabot.yml of the repository in dir, the existing
+// file or the default location if there is none.
+func Target(dir string) string {
+	if path := Find(dir); path != "" {
+		return path
+	}
+	return filepath.Join(dir, FileNames[0])
+}
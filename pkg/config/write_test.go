@@ -0,0 +1,49 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, ".github", "dependabot.yml")
+
+	changed, err := WriteFile(path, []byte("version: 2\n"))
+	require.NoError(t, err)
+	assert.True(t, changed)
+
+	old := time.Now().Add(-time.Hour)
+	require.NoError(t, os.Chtimes(path, old, old))
+	changed, err = WriteFile(path, []byte("version: 2\n"))
+	require.NoError(t, err)
+	assert.False(t, changed)
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, old.Unix(), info.ModTime().Unix())
+
+	changed, err = WriteFile(path, []byte("version: 2\nupdates: []\n"))
+	require.NoError(t, err)
+	assert.True(t, changed)
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "version: 2\nupdates: []\n", string(data))
+
+	entries, err := os.ReadDir(filepath.Dir(path))
+	require.NoError(t, err)
+	assert.Len(t, entries, 1, "no temporary files are left behind")
+}
+
+func TestTarget(t *testing.T) {
+	dir := t.TempDir()
+	assert.Equal(t, filepath.Join(dir, ".github", "dependabot.yml"), Target(dir))
+
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, ".github"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ".github", "dependabot.yaml"), nil, 0o644))
+	assert.Equal(t, filepath.Join(dir, ".github", "dependabot.yaml"), Target(dir))
+}
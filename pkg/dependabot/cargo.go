@@ -0,0 +1,137 @@
+package dependabot
+
+import (
+	"bufio"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/containifyci/dependabot-templater/pkg/search"
+)
+
+var tomlString = regexp.MustCompile(`"([^"]*)"|'([^']*)'`)
+
+type cargoWorkspace struct {
+	root    string
+	members []string
+	exclude []string
+}
+
+// normalizeCargo drops member crates of a workspace whose root manifest
+// lists them, Dependabot updates the whole workspace from its root.
+func normalizeCargo(result *search.Result) []string {
+	var workspaces []cargoWorkspace
+	for _, file := range result.Files {
+		ws, ok := readCargoWorkspace(file)
+		if !ok {
+			continue
+		}
+		ws.root = search.NormalizePath(file)
+		workspaces = append(workspaces, ws)
+	}
+
+	folders := make([]string, 0, len(result.Folders))
+	for _, folder := range result.Folders {
+		if !slices.ContainsFunc(workspaces, func(ws cargoWorkspace) bool { return ws.isMember(folder) }) {
+			folders = append(folders, folder)
+		}
+	}
+	return folders
+}
+
+func (ws cargoWorkspace) isMember(folder string) bool {
+	if folder == ws.root {
+		return false
+	}
+	return matchAny(ws.root, ws.members, folder) && !matchAny(ws.root, ws.exclude, folder)
+}
+
+func matchAny(root string, patterns []string, folder string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := filepath.Match(filepath.Join(root, pattern), folder); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// readCargoWorkspace reads the members and exclude arrays of the [workspace]
+// table. It only understands the subset of TOML used by Cargo manifests.
+func readCargoWorkspace(file string) (cargoWorkspace, bool) {
+	f, err := os.Open(file)
+	if err != nil {
+		return cargoWorkspace{}, false
+	}
+	defer func() {
+		err := f.Close()
+		if err != nil {
+			log.Printf("failed to close file: %s", err)
+		}
+	}()
+
+	var ws cargoWorkspace
+	var found bool
+	var inWorkspace bool
+	var key string
+	var values *[]string
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(stripTomlComment(scanner.Text()))
+		if values != nil {
+			*values = append(*values, tomlStrings(line)...)
+			if strings.Contains(line, "]") {
+				values = nil
+			}
+			continue
+		}
+		if strings.HasPrefix(line, "[") {
+			inWorkspace = line == "[workspace]"
+			found = found || inWorkspace
+			continue
+		}
+		if !inWorkspace {
+			continue
+		}
+		key, line, _ = strings.Cut(line, "=")
+		switch strings.TrimSpace(key) {
+		case "members":
+			values = &ws.members
+		case "exclude":
+			values = &ws.exclude
+		default:
+			continue
+		}
+		*values = append(*values, tomlStrings(line)...)
+		if strings.Contains(line, "]") {
+			values = nil
+		}
+	}
+	return ws, found && len(ws.members) > 0
+}
+
+func tomlStrings(line string) []string {
+	var values []string
+	for _, match := range tomlString.FindAllStringSubmatch(line, -1) {
+		values = append(values, match[1]+match[2])
+	}
+	return values
+}
+
+func stripTomlComment(line string) string {
+	var quote rune
+	for i, r := range line {
+		switch {
+		case quote != 0 && r == quote:
+			quote = 0
+		case quote == 0 && (r == '"' || r == '\''):
+			quote = r
+		case quote == 0 && r == '#':
+			return line[:i]
+		}
+	}
+	return line
+}
@@ -0,0 +1,58 @@
+package dependabot
+
+import (
+	"slices"
+
+	"github.com/containifyci/dependabot-templater/pkg/config"
+	"github.com/containifyci/dependabot-templater/pkg/template"
+)
+
+// resolveCooldown returns the cooldown of an entry, nil when no days are
+// left. The semver days are dropped with a warning for ecosystems without
+// semantic versions, like the whole cooldown for ecosystems without support,
+// so a global cooldown doesn't fail them. A cooldown set for the kind is
+// already rejected by settings.CheckKinds.
+func (d *DependaBot) resolveCooldown(kind, directory, ecosystem string, c *config.Cooldown) *template.Cooldown {
+	if c == nil {
+		return nil
+	}
+	orZero := func(p *int) int {
+		if p == nil {
+			return 0
+		}
+		return *p
+	}
+	cooldown := &template.Cooldown{
+		DefaultDays:     orZero(c.DefaultDays),
+		SemverMajorDays: orZero(c.SemverMajorDays),
+		SemverMinorDays: orZero(c.SemverMinorDays),
+		SemverPatchDays: orZero(c.SemverPatchDays),
+		Include:         c.Include,
+		Exclude:         c.Exclude,
+	}
+	switch {
+	case !slices.Contains(config.CooldownEcosystems, ecosystem):
+		if cooldown.DefaultDays != 0 || cooldown.SemverMajorDays != 0 || cooldown.SemverMinorDays != 0 || cooldown.SemverPatchDays != 0 {
+			d.warning(Warning{Kind: kind, Directory: directory, Msg: "cooldown is not supported for " + ecosystem + ", dropped"})
+		}
+		return nil
+	case !slices.Contains(config.SemverCooldownEcosystems, ecosystem):
+		for _, semver := range []struct {
+			key  string
+			days *int
+		}{
+			{"semver-major-days", &cooldown.SemverMajorDays},
+			{"semver-minor-days", &cooldown.SemverMinorDays},
+			{"semver-patch-days", &cooldown.SemverPatchDays},
+		} {
+			if *semver.days != 0 {
+				d.warning(Warning{Kind: kind, Directory: directory, Msg: semver.key + " is not supported for " + ecosystem + ", dropped"})
+				*semver.days = 0
+			}
+		}
+	}
+	if cooldown.DefaultDays == 0 && cooldown.SemverMajorDays == 0 && cooldown.SemverMinorDays == 0 && cooldown.SemverPatchDays == 0 {
+		return nil
+	}
+	return cooldown
+}
@@ -2,258 +2,609 @@ package dependabot
 
 import (
 	"bytes"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"slices"
 	"strings"
 
+	"github.com/containifyci/dependabot-templater/pkg/codeowners"
+	"github.com/containifyci/dependabot-templater/pkg/config"
 	"github.com/containifyci/dependabot-templater/pkg/search"
+	"github.com/containifyci/dependabot-templater/pkg/settings"
 	"github.com/containifyci/dependabot-templater/pkg/template"
 )
 
-const nilStr = ""
+// Backend selects how the config is rendered.
+type Backend string
 
-func searchTerraform(path string) ([]string, string, error) {
-	foundFolders, err := search.SearchForString(path, "backend")
-	if err != nil {
-		return nil, nilStr, err
+const (
+	// BackendYAML marshals the typed config model, this is the default.
+	BackendYAML Backend = "yaml"
+	// BackendTemplate renders the embedded text templates of the detectors.
+	BackendTemplate Backend = "template"
+)
+
+type DependaBot struct {
+	kinds       []string
+	rootPath    string
+	interval    string
+	day         string
+	time        string
+	timezone    string
+	cronjob     string
+	existing    map[config.Key]config.Schedule
+	reshuffle   bool
+	warn        func(Warning)
+	scanMode    search.Mode
+	scanModeSet bool
+	settings    *settings.Settings
+	settingsErr error
+	backend     Backend
+}
+
+type Option func(*DependaBot)
+
+func WithRootPath(rootPath string) Option {
+	return func(g *DependaBot) {
+		g.rootPath = rootPath
 	}
-	return foundFolders, "dependabot-terraform.yml.tmpl", nil
 }
 
-func searchGithubActions(path string) ([]string, string, error) {
-	foundFolders, err := search.SearchForFiles(path, "action.yml", "action.yaml")
-	if err != nil {
-		return nil, nilStr, err
+func WithKind(kind string) Option {
+	return func(g *DependaBot) {
+		g.kinds = parseKinds(strings.Split(kind, ","))
 	}
+}
 
-	foundFolders2, err := search.SearchForFolder(path, ".github/workflows")
-	if err != nil {
-		return nil, nilStr, err
+func parseKinds(kinds []string) []string {
+	if len(kinds) == 1 && kinds[0] == "all" {
+		return Kinds()
 	}
+	return kinds
+}
 
-	foundFolders = append(foundFolders, foundFolders2...)
-	return normalizeGithubActions(foundFolders), "dependabot-github-actions.yml.tmpl", nil
+// WithScanMode selects which files are considered, see search.Mode.
+func WithScanMode(mode search.Mode) Option {
+	return func(g *DependaBot) {
+		g.scanMode = mode
+		g.scanModeSet = true
+	}
 }
 
-func normalizeGithubActions(folders []string) []string {
-	for i, folder := range folders {
-		if folder == ".github/workflows" {
-			folders[i] = "/"
-		}
+func WithBackend(backend Backend) Option {
+	return func(g *DependaBot) {
+		g.backend = backend
 	}
-	return folders
 }
 
-func searchNPM(path string) ([]string, string, error) {
-	foundFolders, err := search.SearchForFiles(path, "package.json")
-	if err != nil {
-		return nil, nilStr, err
+// WithSettings applies the settings of a config file. Kinds and scan mode
+// set through other options take precedence, as do the schedule options like
+// WithInterval over the schedules of every level. Settings that refer to kinds
+// that aren't registered fail every run in StageSettings.
+func WithSettings(s *settings.Settings) Option {
+	return func(g *DependaBot) {
+		g.settings = s
 	}
-	return normalizeNPM(foundFolders), "dependabot-npm.yml.tmpl", nil
 }
 
-func normalizeNPM(folders []string) []string {
-	validFolder := make([]string, len(folders))
-	counter := 0
-	for _, folder := range folders {
-		if !strings.Contains(folder, "/node_modules/") {
-			validFolder[counter] = folder
-			counter++
-		}
+func WithInterval(interval string) Option {
+	return func(g *DependaBot) {
+		g.interval = interval
 	}
-	return validFolder[:counter]
 }
 
-func searchPython(path string) ([]string, string, error) {
-	foundFolders, err := search.SearchForFiles(path, "requirements.txt", "pyproject.toml")
-	if err != nil {
-		return nil, nilStr, err
+func WithDay(day string) Option {
+	return func(g *DependaBot) {
+		g.day = day
 	}
-	return foundFolders, "dependabot-python.yml.tmpl", nil
 }
 
-func searchGolang(path string) ([]string, string, error) {
-	foundFolders, err := search.SearchForFiles(path, "go.mod")
-	if err != nil {
-		return nil, nilStr, err
+// WithTime sets the time of day of the schedule, formatted as hh:mm.
+func WithTime(time string) Option {
+	return func(g *DependaBot) {
+		g.time = time
 	}
-	return foundFolders, "dependabot-go.yml.tmpl", nil
 }
 
-func searchDocker(path string) ([]string, string, error) {
-	foundFolders, err := search.SearchForFiles(path, "Dockerfile")
-	if err != nil {
-		return nil, nilStr, err
+// WithTimezone sets the IANA time zone of the schedule time.
+func WithTimezone(timezone string) Option {
+	return func(g *DependaBot) {
+		g.timezone = timezone
 	}
-	return foundFolders, "dependabot-docker.yml.tmpl", nil
 }
 
-func searchMaven(path string) ([]string, string, error) {
-	foundFolders, err := search.SearchForFiles(path, "pom.xml")
-	if err != nil {
-		return nil, nilStr, err
+// WithCronjob schedules the entries with a five field cron expression, it
+// implies the cron interval.
+func WithCronjob(cronjob string) Option {
+	return func(g *DependaBot) {
+		g.cronjob = cronjob
 	}
-	return foundFolders, "dependabot-maven.yml.tmpl", nil
 }
 
-func searchGradle(path string) ([]string, string, error) {
-	foundFolders, err := search.SearchForFiles(path, "build.gradle.kts", "build.gradle")
-	if err != nil {
-		return nil, nilStr, err
+func New(opts ...Option) *DependaBot {
+
+	bot := &DependaBot{settings: &settings.Settings{}}
+
+	for _, opt := range opts {
+		opt(bot)
+	}
+
+	if len(bot.kinds) == 0 {
+		bot.kinds = parseKinds(bot.settings.Kinds)
+	}
+	if !bot.scanModeSet {
+		bot.scanMode = bot.settings.ScanMode()
+	}
+	if bot.backend == "" {
+		bot.backend = Backend(bot.settings.Backend)
+	}
+	ecosystems := map[string]string{}
+	for _, detector := range Detectors() {
+		ecosystems[detector.Kind()] = detector.Ecosystem()
 	}
-	return foundFolders, "dependabot-gradle.yml.tmpl", nil
+	bot.settingsErr = bot.settings.CheckKinds(ecosystems)
+
+	return bot
 }
 
-type DependaBot struct {
-	kinds    []string
-	rootPath string
-	interval string
-	day      string
+func (d *DependaBot) Search(path, kind string) (template.DependaBotResult, error) {
+	if _, err := lookup(kind); err != nil {
+		return template.DependaBotResult{}, err
+	}
+	results, err := d.searchAll(path, []string{kind})
+	return results[kind], err
 }
 
-type Option func(*DependaBot)
+// walk returns the raw search results of the kinds.
+func (d *DependaBot) walk(path string, kinds []string) (map[string]*search.Result, error) {
+	matchers := make(map[string]search.Matcher, len(kinds))
+	for _, kind := range kinds {
+		if detector, ok := Lookup(kind); ok {
+			matchers[kind] = detector.Matcher()
+		}
+	}
+	skipped := search.WithSkipped(func(path string, err error) {
+		d.warning(Warning{Directory: path, Msg: "skipped unreadable path: " + err.Error()})
+	})
+	return search.Walk(path, matchers, search.WithMode(d.scanMode), skipped)
+}
 
-func WithRootPath(rootPath string) Option {
-	return func(g *DependaBot) {
-		g.rootPath = rootPath
+// searchAll walks path once for all given kinds. Unknown kinds are ignored.
+func (d *DependaBot) searchAll(path string, kinds []string) (map[string]template.DependaBotResult, error) {
+	found, err := d.walk(path, kinds)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make(map[string]template.DependaBotResult, len(kinds))
+	for _, kind := range kinds {
+		detector, ok := Lookup(kind)
+		if !ok {
+			continue
+		}
+		folders, files := found[kind].Folders, found[kind].Files
+		if normalizer, ok := detector.(Normalizer); ok {
+			folders = normalizer.Normalize(found[kind])
+			files = normalizedFiles(normalizer, files, folders)
+		}
+		results[kind] = template.DependaBotResult{
+			Folders:   folders,
+			Files:     files,
+			Template:  detector.Template(),
+			Ecosystem: detector.Ecosystem(),
+			Registry:  detector.Registry(),
+			Interval:  d.interval,
+			Day:       d.day,
+		}
 	}
+	return results, nil
 }
 
-func WithKind(kind string) Option {
-	return func(g *DependaBot) {
-		var kinds []string
-		if kind == "all" {
-			kinds = []string{"gha", "docker", "terraform", "go", "gradle", "maven", "npm", "python"}
-		} else {
-			kinds = strings.Split(kind, ",")
+// normalizedFiles keeps the files whose folder is one of the normalized
+// folders. Every file is normalized on its own so that folders moved by the
+// normalizer, like .github/workflows to /, keep their files.
+func normalizedFiles(normalizer Normalizer, files, folders []string) []string {
+	kept := make([]string, 0, len(files))
+	for _, file := range files {
+		normalized := normalizer.Normalize(&search.Result{Folders: []string{search.NormalizePath(file)}, Files: []string{file}})
+		if len(normalized) == 1 && slices.Contains(folders, normalized[0]) {
+			kept = append(kept, file)
 		}
-		g.kinds = kinds
 	}
+	return kept
 }
 
-func WithInterval(interval string) Option {
-	return func(g *DependaBot) {
-		g.interval = interval
+// GenarateConfigFile renders the config for all kinds and panics on any error.
+//
+// Deprecated: use GenerateConfigFile which reports failures per kind.
+func (d *DependaBot) GenarateConfigFile(path string) ([]string, string) {
+	packages, config, err := d.GenerateConfigFile(path)
+	if err != nil {
+		panic(err)
 	}
+	return packages, config
 }
 
-func WithDay(day string) Option {
-	return func(g *DependaBot) {
-		g.day = day
+// GenerateConfigFile renders the config for all kinds. Kinds that fail are left
+// out of the config and reported through a *GenerateError, so the returned
+// config is still usable for the remaining kinds.
+func (d *DependaBot) GenerateConfigFile(path string) ([]string, string, error) {
+	errs := &GenerateError{}
+	found := d.collect(path, errs)
+
+	if d.backend == BackendTemplate {
+		packages, out := d.renderTemplates(path, found, errs)
+		cfg, err := config.Parse([]byte(out))
+		if err != nil {
+			errs.add("", path, StageValidate, err)
+		} else {
+			d.validate(path, cfg, found, errs)
+		}
+		return packages, out, errs.errOrNil()
 	}
+
+	packages, cfg := d.buildConfig(found)
+	d.validate(path, cfg, found, errs)
+	out, err := cfg.Marshal()
+	if err != nil {
+		errs.add("", path, StageRender, err)
+	}
+	return packages, string(out), errs.errOrNil()
 }
 
-func New(opts ...Option) *DependaBot {
+// Generate returns the typed config for all kinds, failures are reported like
+// in GenerateConfigFile.
+func (d *DependaBot) Generate(path string) ([]string, *config.Config, error) {
+	errs := &GenerateError{}
+	found := d.collect(path, errs)
+	packages, cfg := d.buildConfig(found)
+	d.validate(path, cfg, found, errs)
+	return packages, cfg, errs.errOrNil()
+}
 
-	bot := &DependaBot{}
+// MergeConfigFile merges the generated entries into the existing
+// dependabot.yml, see config.Merge. Stale entries are handled as configured in
+// the settings.
+func (d *DependaBot) MergeConfigFile(path string, existing []byte) ([]string, string, error) {
+	errs := &GenerateError{}
+	found := d.collect(path, errs)
+	packages, cfg := d.buildConfig(found)
+	d.validate(path, cfg, found, errs)
+	out, _, err := config.Merge(existing, cfg, d.mergeOptions()...)
+	if err != nil {
+		errs.add("", path, StageMerge, err)
+	}
+	return packages, string(out), errs.errOrNil()
+}
 
-	for _, opt := range opts {
-		opt(bot)
+// CheckConfigFile compares the committed dependabot.yml with the config that
+// would be written for path, a merge in case merging is enabled in the
+// settings. name is the file name shown in the diff.
+func (d *DependaBot) CheckConfigFile(path, name string, committed []byte) (*config.Drift, error) {
+	errs := &GenerateError{}
+	found := d.collect(path, errs)
+	_, expected := d.buildConfig(found)
+	d.validate(path, expected, found, errs)
+
+	current, err := config.Parse(committed)
+	if err != nil {
+		errs.add("", name, StageCheck, err)
+		return nil, errs
+	}
+	if d.settings.Merge {
+		merged, _, err := config.Merge(committed, expected, d.mergeOptions()...)
+		if err == nil {
+			expected, err = config.Parse(merged)
+		}
+		if err != nil {
+			errs.add("", path, StageMerge, err)
+			return nil, errs
+		}
+	}
+	if current.Version == 0 && len(current.Updates) == 0 {
+		current = config.New()
 	}
 
-	return bot
+	drift, err := config.Compare(name, current, expected)
+	if err != nil {
+		errs.add("", path, StageCheck, err)
+	}
+	return drift, errs.errOrNil()
 }
 
-func (d *DependaBot) Search(path, kind string) (template.DependaBotResult, error) {
-	var folders []string
-	var tmplfile string
-	var err error
-	switch kind {
-	case "gha":
-		folders, tmplfile, err = searchGithubActions(path)
-	case "docker":
-		folders, tmplfile, err = searchDocker(path)
-	case "go":
-		folders, tmplfile, err = searchGolang(path)
-	case "gradle":
-		folders, tmplfile, err = searchGradle(path)
-	case "maven":
-		folders, tmplfile, err = searchMaven(path)
-	case "npm":
-		folders, tmplfile, err = searchNPM(path)
-	case "python":
-		folders, tmplfile, err = searchPython(path)
-	case "terraform":
-		folders, tmplfile, err = searchTerraform(path)
-	}
-	return template.DependaBotResult{Folders: folders, Template: tmplfile, Registry: registry(kind), Interval: d.interval, Day: d.day}, err
-}
-
-func registry(kind string) string {
-	switch kind {
-	case "npm":
-		return "npm-registry"
-	case "python":
-		return "python-registry"
-	case "gha":
-		fallthrough
-	case "docker":
-		fallthrough
-	case "go":
-		fallthrough
-	case "gradle":
-		fallthrough
-	case "maven":
-		fallthrough
-	case "terraform":
-		fallthrough
-	default:
-		return ""
+// mergeOptions handles the stale entries as configured in the settings. Only
+// entries of the generated kinds can be stale, the others are kept, and only
+// the keys the settings set are dropped from the updated entries.
+func (d *DependaBot) mergeOptions() []config.MergeOption {
+	ecosystems := []string{}
+	for _, kind := range d.kinds {
+		if detector, ok := Lookup(kind); ok {
+			ecosystems = append(ecosystems, detector.Ecosystem())
+		}
+	}
+	return []config.MergeOption{
+		config.WithStale(config.Stale(d.settings.Stale)),
+		config.WithEcosystems(ecosystems...),
+		config.WithOwnedKeys(d.settings.UpdateKeys()...),
 	}
 }
 
-func (d *DependaBot) GenarateConfigFile(path string) ([]string, string) {
-	var buffer bytes.Buffer
-	packages := make([]string, 0)
+// validate checks the generated config. Problems of an update are reported for
+// the kind that produced it. Dependabot may support ecosystems this version
+// doesn't know yet, an unknown ecosystem of a detector that allows it, see
+// AllowUnknownEcosystem, is only a warning.
+func (d *DependaBot) validate(path string, cfg *config.Config, found []kindResult, errs *GenerateError) {
+	var problems config.Errors
+	if !errors.As(cfg.Validate(), &problems) {
+		return
+	}
+	var updates []kindResult
+	for _, kr := range found {
+		for range kr.result.Entries {
+			updates = append(updates, kr)
+		}
+	}
+	for _, problem := range problems {
+		var i int
+		if _, err := fmt.Sscanf(problem.Path, "updates[%d]", &i); err != nil || i >= len(updates) {
+			errs.add("", path, StageValidate, problem)
+			continue
+		}
+		kr := updates[i]
+		if problem.Path == fmt.Sprintf("updates[%d].package-ecosystem", i) && allowsUnknownEcosystem(kr.detector) {
+			d.warning(Warning{Kind: kr.kind, Directory: cfg.Updates[i].Directory, Msg: problem.Msg})
+			continue
+		}
+		errs.add(kr.kind, path, StageValidate, problem)
+	}
+}
+
+// allowsUnknownEcosystem reports whether the unknown ecosystem of detector is
+// only a warning.
+func allowsUnknownEcosystem(detector Detector) bool {
+	checker, ok := detector.(EcosystemChecker)
+	return ok && checker.AllowsUnknownEcosystem() && !slices.Contains(config.Ecosystems, detector.Ecosystem())
+}
 
-	var foundKinds = make([]string, 0)
+// kindResult is the search result of a kind with the resolved entries.
+type kindResult struct {
+	kind     string
+	detector Detector
+	result   template.DependaBotResult
+}
+
+// collect searches path for all kinds and resolves the entries of the found folders.
+func (d *DependaBot) collect(path string, errs *GenerateError) []kindResult {
+	if d.settingsErr != nil {
+		errs.add("", path, StageSettings, d.settingsErr)
+		return nil
+	}
+	var kinds []string
 	for _, kind := range d.kinds {
-		result, err := d.Search(path, kind)
-		if err != nil {
-			panic(err)
+		if _, err := lookup(kind); err != nil {
+			errs.add(kind, path, StageSearch, err)
+			continue
 		}
+		kinds = append(kinds, kind)
+	}
+
+	results, err := d.searchAll(path, kinds)
+	if err != nil {
+		for _, kind := range kinds {
+			errs.add(kind, path, StageSearch, err)
+		}
+	}
+
+	owners := d.codeOwners(path, errs)
+	found := make([]kindResult, 0, len(kinds))
+	for _, kind := range kinds {
+		result := results[kind]
 		if len(result.Folders) <= 0 {
 			continue
 		}
 
-		result.Folders = normalizeFolders(result.Folders)
-		for i, folder := range result.Folders {
-			result.Folders[i] = replacePrefix(folder, d.rootPath, ".")
+		folders := make([]string, 0, len(result.Folders))
+		for _, folder := range result.Folders {
+			folder = replacePrefix(folder, d.rootPath, ".")
+			if d.settings.Included(folder) {
+				folders = append(folders, folder)
+			}
 		}
+		if len(folders) == 0 {
+			continue
+		}
+		result.Folders = folders
+		result.Files = d.includedFiles(result.Files)
+		result.Entries = d.entries(kind, result, owners)
+
+		detector, _ := Lookup(kind)
+		found = append(found, kindResult{kind: kind, detector: detector, result: result})
+	}
+	return found
+}
 
-		packages = append(packages, kind)
-		dependabot, err := template.RenderDependaBot(result)
+// includedFiles returns the files in an included folder, relative like the folders.
+func (d *DependaBot) includedFiles(files []string) []string {
+	included := make([]string, 0, len(files))
+	for _, file := range files {
+		folder := replacePrefix(search.NormalizePath(file), d.rootPath, ".")
+		if d.settings.Included(folder) {
+			included = append(included, filepath.Join(folder, filepath.Base(file)))
+		}
+	}
+	return included
+}
+
+// Detection is what was found for a kind, see Detect.
+type Detection struct {
+	Kind      string   `json:"kind" yaml:"kind"`
+	Ecosystem string   `json:"ecosystem" yaml:"ecosystem"`
+	Folders   []string `json:"folders" yaml:"folders"`
+	Registry  string   `json:"registry,omitempty" yaml:"registry,omitempty"`
+	// Files are the matched files of the Folders, files of folders dropped
+	// by the normalizer are left out.
+	Files []string `json:"files" yaml:"files"`
+}
+
+// Detect returns the folders and files found for every kind without
+// generating a config. Kinds without folders are left out, failures are
+// reported like in GenerateConfigFile.
+func (d *DependaBot) Detect(path string) ([]Detection, error) {
+	errs := &GenerateError{}
+	found := d.collect(path, errs)
+	detections := make([]Detection, 0, len(found))
+	for _, kr := range found {
+		detections = append(detections, Detection{
+			Kind:      kr.kind,
+			Ecosystem: kr.result.Ecosystem,
+			Folders:   kr.result.Folders,
+			Registry:  kr.result.Registry,
+			Files:     kr.result.Files,
+		})
+	}
+	return detections, errs.errOrNil()
+}
+
+// renderTemplates renders the found kinds with their embedded templates.
+func (d *DependaBot) renderTemplates(path string, found []kindResult, errs *GenerateError) ([]string, string) {
+	var buffer bytes.Buffer
+	packages := make([]string, 0)
+	referenced := make(map[string]bool)
+	for _, kr := range found {
+		dependabot, err := template.RenderDependaBot(kr.result)
 		if err != nil {
-			panic(err)
+			errs.add(kr.kind, path, StageRender, err)
+			continue
+		}
+		packages = append(packages, kr.kind)
+		for _, entry := range kr.result.Entries {
+			for _, name := range entry.Registries {
+				referenced[name] = true
+			}
 		}
-		foundKinds = append(foundKinds, kind)
 		buffer.WriteString(dependabot)
 	}
 	buffer.WriteString("\n")
 
 	var buffer2 bytes.Buffer
-	header, err := template.RenderHeader(foundKinds)
+	header, err := template.RenderHeader(d.templateRegistries(referenced))
 	if err != nil {
-		panic(err)
+		errs.add("", path, StageHeader, err)
 	}
 	buffer2.WriteString(strings.Trim(header, "\n"))
-	// buffer2.WriteString("\n")
 	buffer2.WriteString(buffer.String())
 	return packages, buffer2.String()
 }
 
-func normalizeFolders(folders []string) []string {
-	validFolder := make([]string, len(folders))
-	counter := 0
-	for _, folder := range folders {
-		switch {
-		case strings.Contains(folder, "/node_modules/"),
-			strings.Contains(folder, ".terraform/"),
-			strings.Contains(folder, "/vendor/"):
-			continue
+// entries resolves the options of every folder found for kind.
+func (d *DependaBot) entries(kind string, result template.DependaBotResult, owners *codeowners.File) []template.DependaBotEntry {
+	s := d.settings
+	// The schedule options override the schedules of every level.
+	explicit := settings.Options{Schedule: &settings.Schedule{
+		Interval: d.interval,
+		Day:      d.day,
+		Time:     d.time,
+		Timezone: d.timezone,
+		Cronjob:  d.cronjob,
+	}}
+
+	entries := make([]template.DependaBotEntry, 0, len(result.Folders))
+	for _, folder := range result.Folders {
+		opts := s.Resolve(kind, folder).Merge(explicit)
+		key := config.Key{Ecosystem: result.Ecosystem, Directory: folder, TargetBranch: opts.TargetBranch}
+		schedule := d.stagger(key, defaultSchedule(opts.Schedule))
+		registries := s.RegistriesFor(kind, result.Ecosystem, folder)
+		if _, ok := s.Registries[result.Registry]; ok && !slices.Contains(registries, result.Registry) {
+			registries = append([]string{result.Registry}, registries...)
+		}
+		entry := template.DependaBotEntry{
+			Directory:             folder,
+			Registries:            registries,
+			Interval:              schedule.Interval,
+			Day:                   schedule.Day,
+			Time:                  schedule.Time,
+			Timezone:              schedule.Timezone,
+			Cronjob:               schedule.Cronjob,
+			Labels:                opts.Labels,
+			Assignees:             opts.Assignees,
+			Reviewers:             opts.Reviewers,
+			Milestone:             opts.Milestone,
+			TargetBranch:          opts.TargetBranch,
+			OpenPullRequestsLimit: opts.OpenPullRequestsLimit,
+			RebaseStrategy:        opts.RebaseStrategy,
+			Groups:                resolveGroups(result.Ecosystem, opts),
+		}
+		entry.Ignore, entry.Allow = resolveRules(result.Ecosystem, opts)
+		if securityOnly(opts) {
+			limit := 0
+			entry.OpenPullRequestsLimit = &limit
+		}
+		if opts.PullRequestBranchName != nil {
+			entry.BranchNameSeparator = opts.PullRequestBranchName.Separator
+		}
+		entry.Cooldown = d.resolveCooldown(kind, entry.Directory, result.Ecosystem, opts.Cooldown)
+		if opts.CommitMessage != nil {
+			entry.CommitMessagePrefix = opts.CommitMessage.Prefix
+			entry.CommitMessagePrefixDevelopment = opts.CommitMessage.PrefixDevelopment
+			entry.CommitMessageInclude = opts.CommitMessage.Include
+		}
+		if owners != nil {
+			d.applyOwners(kind, &entry, owners)
 		}
-		validFolder[counter] = folder
-		counter++
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+// templateRegistries returns the definitions of the referenced registries sorted by name.
+func (d *DependaBot) templateRegistries(referenced map[string]bool) []template.Registry {
+	names := make([]string, 0, len(referenced))
+	for name := range referenced {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	registries := make([]template.Registry, 0, len(names))
+	for _, name := range names {
+		r := d.settings.Registries[name]
+		registries = append(registries, template.Registry{
+			Name:                 name,
+			Type:                 r.Type,
+			URL:                  r.URL,
+			Username:             r.Username,
+			Password:             r.Password,
+			Key:                  r.Key,
+			Token:                r.Token,
+			Organization:         r.Organization,
+			Repo:                 r.Repo,
+			AuthKey:              r.AuthKey,
+			PublicKeyFingerprint: r.PublicKeyFingerprint,
+			ReplacesBase:         r.ReplacesBase,
+		})
+	}
+	return registries
+}
+
+// defaultSchedule runs weekly on sundays unless configured otherwise. Values
+// of other levels that don't apply to the resolved interval are dropped.
+func defaultSchedule(s *settings.Schedule) settings.Schedule {
+	schedule := settings.Schedule{}
+	if s != nil {
+		schedule = *s
+	}
+	if schedule.Interval == "" {
+		schedule.Interval = "weekly"
+	}
+	if schedule.Interval != "weekly" {
+		schedule.Day = ""
+	} else if schedule.Day == "" {
+		schedule.Day = "sunday"
+	}
+	if schedule.Interval == "cron" {
+		schedule.Time = ""
 	}
-	return validFolder[:counter]
+	return schedule
 }
 
 func replacePrefix(input, prefix, replacement string) string {
@@ -1,11 +1,19 @@
 package dependabot
 
 import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
+	"github.com/containifyci/dependabot-templater/pkg/config"
 	. "github.com/containifyci/dependabot-templater/pkg/dependabot/testdata"
+	"github.com/containifyci/dependabot-templater/pkg/search"
+	"github.com/containifyci/dependabot-templater/pkg/settings"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestRender(t *testing.T) {
@@ -58,9 +66,15 @@ func TestRender(t *testing.T) {
 			kind:             "python",
 			expectedTemplate: PythonConfig(),
 		},
+		{
+			name:             "Cargo workspace relative to the current directory level 2",
+			path:             "../../pkg/dependabot/test_path/",
+			kind:             "cargo",
+			expectedTemplate: CargoConfig(),
+		},
 	} {
 		t.Run(test.name, func(t *testing.T) {
-			bot := New(WithKind(test.kind), WithRootPath("dependabot/test_path/"))
+			bot := New(WithKind(test.kind), WithRootPath("dependabot/test_path/"), WithSettings(registrySettings(t)), WithBackend(BackendTemplate))
 			_, tmpl := bot.GenarateConfigFile(test.path)
 
 			assert.Equal(t, test.expectedTemplate, tmpl)
@@ -68,6 +82,154 @@ func TestRender(t *testing.T) {
 	}
 }
 
+func TestRenderYAML(t *testing.T) {
+	for _, test := range []struct {
+		kind     string
+		expected string
+	}{
+		{kind: "terraform", expected: YAMLTerraformConfig()},
+		{kind: "python", expected: YAMLPythonConfig()},
+	} {
+		t.Run(test.kind, func(t *testing.T) {
+			bot := New(WithKind(test.kind), WithRootPath("dependabot/test_path/"), WithSettings(registrySettings(t)))
+			_, out, err := bot.GenerateConfigFile("../../pkg/dependabot/test_path/")
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, out)
+		})
+	}
+}
+
+func TestBackendsEquivalent(t *testing.T) {
+	generateBoth(t, registrySettings(t), Kinds())
+}
+
+// generateBoth generates kinds with both backends, asserts that the template
+// output parses to what the yaml backend generates and returns the updates of
+// all kinds by key. opts only apply to the combined run, so observers such as
+// WithWarnings see every directory once.
+func generateBoth(t *testing.T, cfg *settings.Settings, kinds []string, opts ...Option) map[config.Key]config.Update {
+	t.Helper()
+	for _, kind := range kinds {
+		t.Run(kind, func(t *testing.T) {
+			opts := []Option{WithKind(kind), WithRootPath("dependabot/test_path/"), WithSettings(cfg)}
+			_, tmpl, err := New(append(opts, WithBackend(BackendTemplate))...).GenerateConfigFile("./test_path/")
+			require.NoError(t, err)
+			_, generated, err := New(opts...).Generate("./test_path/")
+			require.NoError(t, err)
+
+			parsed, err := config.Parse([]byte(tmpl))
+			require.NoError(t, err)
+			assert.Equal(t, generated, parsed)
+		})
+	}
+
+	opts = append([]Option{WithKind(strings.Join(kinds, ",")), WithRootPath("dependabot/test_path/"), WithSettings(cfg)}, opts...)
+	_, generated, err := New(opts...).Generate("./test_path/")
+	require.NoError(t, err)
+	updates := map[config.Key]config.Update{}
+	for _, u := range generated.Updates {
+		updates[u.Key()] = u
+	}
+	return updates
+}
+
+func TestUpdateOptions(t *testing.T) {
+	cfg, err := settings.Parse("config.yaml", []byte(`labels: [dependencies]
+reviewers: [platform-team]
+milestone: 4
+commit-message:
+  prefix: "deps"
+  prefix-development: "deps(dev)"
+pull-request-branch-name:
+  separator: "-"
+ecosystems:
+  terraform:
+    labels: [dependencies, "area: infra"]
+    open-pull-requests-limit: 0
+    rebase-strategy: auto
+directories:
+  - path: test_path/projecte
+    assignees: [octocat]
+    target-branch: develop
+    commit-message:
+      prefix: "pip"
+`))
+	require.NoError(t, err)
+
+	updates := generateBoth(t, cfg, []string{"python", "terraform", "go"})
+
+	pip := updates[config.Key{Ecosystem: "pip", Directory: "test_path/projecte", TargetBranch: "develop"}]
+	assert.Equal(t, []string{"dependencies"}, pip.Labels)
+	assert.Equal(t, []string{"octocat"}, pip.Assignees)
+	assert.Equal(t, []string{"platform-team"}, pip.Reviewers)
+	assert.Equal(t, 4, pip.Milestone)
+	assert.Equal(t, &config.CommitMessage{Prefix: "pip", PrefixDevelopment: "deps(dev)", Include: "scope"}, pip.CommitMessage)
+	assert.Equal(t, &config.BranchName{Separator: "-"}, pip.PullRequestBranchName)
+
+	terraform := updates[config.Key{Ecosystem: "terraform", Directory: "test_path/projectb"}]
+	assert.Equal(t, []string{"dependencies", "area: infra"}, terraform.Labels)
+	assert.Equal(t, "auto", terraform.RebaseStrategy)
+	require.NotNil(t, terraform.OpenPullRequestsLimit)
+	assert.Equal(t, 0, *terraform.OpenPullRequestsLimit)
+}
+
+func TestCooldown(t *testing.T) {
+	cfg, err := settings.Parse("config.yaml", []byte(`cooldown:
+  default-days: 3
+  semver-major-days: 30
+ecosystems:
+  python:
+    cooldown:
+      semver-minor-days: 7
+      exclude: ["internal-*"]
+directories:
+  - path: test_path/projectf
+    cooldown:
+      default-days: 0
+      semver-major-days: 0
+  - path: test_path/projecte
+    cooldown: {}
+`))
+	require.NoError(t, err)
+
+	var warnings []Warning
+	updates := generateBoth(t, cfg, []string{"python", "docker"}, WithWarnings(func(w Warning) { warnings = append(warnings, w) }))
+	assert.Equal(t, &config.Cooldown{DefaultDays: days(3), SemverMajorDays: days(30), SemverMinorDays: days(7), Exclude: []string{"internal-*"}},
+		updates[config.Key{Ecosystem: "pip", Directory: "test_path/projecte"}].Cooldown)
+	assert.Equal(t, &config.Cooldown{SemverMinorDays: days(7), Exclude: []string{"internal-*"}},
+		updates[config.Key{Ecosystem: "pip", Directory: "test_path/projectf"}].Cooldown)
+	assert.Equal(t, &config.Cooldown{DefaultDays: days(3)}, updates[config.Key{Ecosystem: "docker", Directory: "test_path/projecth"}].Cooldown)
+	assert.Contains(t, warnings, Warning{Kind: "docker", Directory: "test_path/projecth", Msg: "semver-major-days is not supported for docker, dropped"})
+
+	cfg, err = settings.Parse("config.yaml", []byte("cooldown:\n  semver-major-days: 30\n"))
+	require.NoError(t, err)
+	updates = generateBoth(t, cfg, []string{"docker"})
+	assert.Nil(t, updates[config.Key{Ecosystem: "docker", Directory: "test_path/projecth"}].Cooldown)
+}
+
+func TestCooldownUnsupported(t *testing.T) {
+	Register(NewDetector("submodules", "gitsubmodule", search.Files("test.txt"), "dependabot-submodules.yml.tmpl"))
+	t.Cleanup(func() { Unregister("submodules") })
+
+	cfg, err := settings.Parse("config.yaml", []byte("cooldown:\n  default-days: 3\n"))
+	require.NoError(t, err)
+	var warnings []Warning
+	_, generated, err := New(WithKind("submodules"), WithRootPath("dependabot/test_path/"), WithSettings(cfg),
+		WithWarnings(func(w Warning) { warnings = append(warnings, w) })).Generate("./test_path/")
+	require.NoError(t, err)
+	require.Len(t, generated.Updates, 1)
+	assert.Nil(t, generated.Updates[0].Cooldown)
+	assert.Equal(t, []Warning{{Kind: "submodules", Directory: "test_path/projecta", Msg: "cooldown is not supported for gitsubmodule, dropped"}}, warnings)
+
+	cfg, err = settings.Parse("config.yaml", []byte("ecosystems:\n  submodules:\n    cooldown:\n      default-days: 3\n"))
+	require.NoError(t, err)
+	_, _, err = New(WithKind("submodules"), WithRootPath("dependabot/test_path/"), WithSettings(cfg)).Generate("./test_path/")
+	var genErr *GenerateError
+	require.ErrorAs(t, err, &genErr)
+	assert.Equal(t, StageSettings, genErr.Errors[0].Stage)
+	assert.Contains(t, err.Error(), "config.yaml:4: ecosystems.submodules.cooldown: cooldown is not supported for gitsubmodule")
+}
+
 func TestReplacePrefix(t *testing.T) {
 	for _, test := range []struct {
 		name         string
@@ -143,6 +305,26 @@ func TestSearch(t *testing.T) {
 			name:    "Gradle",
 			folders: []string{"test_path/projectj", "test_path/projectk"},
 			kind:    "gradle",
+		}, {
+			name:    "Cargo",
+			folders: []string{"test_path/projectrust", "test_path/projectrust/crates/legacy"},
+			kind:    "cargo",
+		}, {
+			name:    "Composer",
+			folders: []string{"test_path/projectphp"},
+			kind:    "composer",
+		}, {
+			name:    "Bundler",
+			folders: []string{"test_path/projectgem", "test_path/projectruby"},
+			kind:    "bundler",
+		}, {
+			name:    "Mix umbrella",
+			folders: []string{"test_path/projectelixir"},
+			kind:    "mix",
+		}, {
+			name:    "NuGet solution",
+			folders: []string{"test_path/projectdotnet", "test_path/projectfsharp"},
+			kind:    "nuget",
 		},
 	} {
 		t.Run(test.name, func(t *testing.T) {
@@ -154,21 +336,343 @@ func TestSearch(t *testing.T) {
 	}
 }
 
-func TestNormalizeFolders(t *testing.T) {
-	folders := []string{
-		"test_path/projecta",
-		"test_path/projectb",
-		"test_path/projectc",
-		"test_path/projectd",
-		"test_path/projectc/node_modules/package/awesome",
-		"test_path/projectc/.terraform/package/awesome",
-		"test_path/projectc/vendor/package/awesome",
+func TestGenerateConfigFileErrors(t *testing.T) {
+	bot := New(WithKind("terraform,npm"), WithRootPath("dependabot/test_path/"))
+	packages, _, err := bot.GenerateConfigFile("./test_path/missing")
+
+	var genErr *GenerateError
+	assert.ErrorAs(t, err, &genErr)
+	assert.Empty(t, packages)
+	assert.Len(t, genErr.Errors, 2)
+	assert.Equal(t, []string{"terraform", "npm"}, genErr.Kinds(StageSearch))
+	assert.ErrorIs(t, err, fs.ErrNotExist)
+	assert.Equal(t, "./test_path/missing", genErr.Errors[0].Path)
+}
+
+func TestGenerateConfigFileSkipsUnreadable(t *testing.T) {
+	dir := t.TempDir()
+	for _, file := range []string{"app/go.mod", "web/package.json"} {
+		require.NoError(t, os.MkdirAll(filepath.Dir(filepath.Join(dir, file)), 0o755))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, file), nil, 0o644))
+	}
+	// A nesting beyond the maximum path length can't be read, even as root.
+	root, err := os.OpenRoot(dir)
+	require.NoError(t, err)
+	name := strings.Repeat("d", 250)
+	for range 20 {
+		require.NoError(t, root.Mkdir(name, 0o755))
+		next, err := root.OpenRoot(name)
+		require.NoError(t, err)
+		require.NoError(t, root.Close())
+		root = next
 	}
+	require.NoError(t, root.Close())
+
+	var warnings []Warning
+	bot := New(WithKind("go,npm"), WithWarnings(func(w Warning) { warnings = append(warnings, w) }))
+	packages, _, err := bot.GenerateConfigFile(dir)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"go", "npm"}, packages)
+	require.Len(t, warnings, 1)
+	assert.True(t, strings.HasPrefix(warnings[0].Directory, filepath.Join(dir, name)), warnings[0].Directory)
+	assert.Contains(t, warnings[0].Msg, "skipped unreadable path")
+}
+
+func TestGenerateConfigFileUnknownKinds(t *testing.T) {
+	cfg, err := settings.Parse("config.yaml", []byte(`ecosystems:
+  golang:
+    labels: [go]
+`))
+	require.NoError(t, err)
+
+	_, _, err = New(WithKind("go"), WithSettings(cfg), WithRootPath("dependabot/test_path/")).GenerateConfigFile("./test_path/")
+	var genErr *GenerateError
+	require.ErrorAs(t, err, &genErr)
+	require.Len(t, genErr.Errors, 1)
+	assert.Equal(t, StageSettings, genErr.Errors[0].Stage)
+	assert.Contains(t, err.Error(), `config.yaml:3: ecosystems.golang: unknown kind "golang", expected one of [`)
+}
+
+func TestScanModeGitignore(t *testing.T) {
+	dir := t.TempDir()
+	for _, file := range []string{".git/HEAD", ".gitignore", "requirements.txt", ".venv/lib/pkg/requirements.txt"} {
+		require.NoError(t, os.MkdirAll(filepath.Dir(filepath.Join(dir, file)), 0o755))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, file), []byte(".venv/\n"), 0o644))
+	}
+
+	bot := New(WithKind("python"), WithScanMode(search.ModeGitignore))
+	result, err := bot.Search(dir, "python")
+	require.NoError(t, err)
+	assert.Equal(t, []string{dir}, result.Folders)
+
+	result, err = New(WithKind("python")).Search(dir, "python")
+	require.NoError(t, err)
+	assert.Len(t, result.Folders, 2)
+}
+
+func TestGenerateConfigFileWithSettings(t *testing.T) {
+	cfg, err := settings.Parse("config.yaml", []byte(`kinds: [python, go]
+exclude: ["test_path/projectf"]
+schedule:
+  interval: monthly
+ecosystems:
+  go:
+    schedule:
+      interval: weekly
+      day: tuesday
+`))
+	require.NoError(t, err)
+
+	bot := New(WithSettings(cfg), WithRootPath("dependabot/test_path/"))
+	packages, config, err := bot.GenerateConfigFile("./test_path/")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"python", "go"}, packages)
+	assert.Contains(t, config, "directory: test_path/projecte\n")
+	assert.NotContains(t, config, "directory: test_path/projectf\n")
+	assert.Contains(t, config, "interval: monthly\n")
+	assert.Contains(t, config, "interval: weekly\n      day: tuesday\n")
+
+	bot = New(WithSettings(cfg), WithKind("go"), WithInterval("daily"), WithRootPath("dependabot/test_path/"))
+	packages, config, err = bot.GenerateConfigFile("./test_path/")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"go"}, packages)
+	assert.Contains(t, config, "interval: daily\n")
+	assert.NotContains(t, config, "day: tuesday")
+}
+
+func TestSchedule(t *testing.T) {
+	cfg, err := settings.Parse("config.yaml", []byte(`kinds: [python]
+schedule:
+  time: "09:00"
+  timezone: Europe/Berlin
+directories:
+  - path: test_path/projectf
+    schedule:
+      cronjob: "0 3 * * 1-5"
+      timezone: Asia/Tokyo
+`))
+	require.NoError(t, err)
+
+	for _, backend := range []Backend{BackendYAML, BackendTemplate} {
+		t.Run(string(backend), func(t *testing.T) {
+			bot := New(WithSettings(cfg), WithBackend(backend), WithRootPath("dependabot/test_path/"))
+			_, generated, err := bot.Generate("./test_path/")
+			require.NoError(t, err)
+			require.Len(t, generated.Updates, 2)
+			assert.Equal(t, config.Schedule{Interval: "weekly", Day: "sunday", Time: "09:00", Timezone: "Europe/Berlin"}, generated.Updates[0].Schedule)
+			assert.Equal(t, config.Schedule{Interval: "cron", Cronjob: "0 3 * * 1-5", Timezone: "Asia/Tokyo"}, generated.Updates[1].Schedule)
+		})
+	}
+
+	bot := New(WithSettings(cfg), WithCronjob("0 25 * * *"), WithRootPath("dependabot/test_path/"))
+	_, _, err = bot.GenerateConfigFile("./test_path/")
+	var genErr *GenerateError
+	require.ErrorAs(t, err, &genErr)
+	// The option overrides the cronjob of projectf as well.
+	assert.Equal(t, []string{"python", "python"}, genErr.Kinds(StageValidate))
+	assert.Contains(t, err.Error(), `cronjob "0 25 * * *": invalid hour "25"`)
+}
+
+func TestRegistries(t *testing.T) {
+	cfg, err := settings.Parse("config.yaml", []byte(`registries:
+  git-modules:
+    type: git
+    url: https://github.com
+    username: x-access-token
+    password: ${{ secrets.PAT }}
+    directories: ["test_path/projectb"]
+  maven-central:
+    type: maven-repository
+    url: https://maven.example.com
+    kinds: [maven, gradle]
+  unused:
+    type: docker-registry
+    url: https://registry.example.com
+    kinds: [docker]
+`))
+	require.NoError(t, err)
+
+	bot := New(WithKind("terraform,gradle,maven,npm"), WithRootPath("dependabot/test_path/"), WithSettings(cfg))
+	_, config, err := bot.GenerateConfigFile("./test_path/")
+	require.NoError(t, err)
+	assert.Contains(t, config, "registries:\n  git-modules:\n    type: git\n")
+	assert.Contains(t, config, "  maven-central:\n    type: maven-repository\n")
+	assert.NotContains(t, config, "unused")
+	assert.Contains(t, config, "directory: test_path/projectb\n")
+	assert.Equal(t, 1, strings.Count(config, "      - git-modules\n"))
+	assert.Equal(t, 3, strings.Count(config, "      - maven-central\n"))
+}
+
+// registrySettings defines the registries the npm and python fixtures reference.
+func registrySettings(t *testing.T) *settings.Settings {
+	t.Helper()
+	cfg, err := settings.Parse("config.yaml", []byte(`registries:
+  npm-registry:
+    type: npm-registry
+    url: https://europe-west3-npm.pkg.dev/xxxxxxx/npm-registry
+    username: _json_key_base64
+    password: ${{ secrets.ARTIFACTORY_REGISTRY_SERVICE_ACCOUNT_KEY_BASE64 }}
+    kinds: [npm]
+  python-registry:
+    type: python-index
+    url: https://europe-west3-python.pkg.dev/xxxxxxx/python-registry
+    username: _json_key_base64
+    password: ${{ secrets.ARTIFACTORY_REGISTRY_SERVICE_ACCOUNT_KEY_BASE64 }}
+    kinds: [python]
+`))
+	require.NoError(t, err)
+	return cfg
+}
+
+func TestMergeConfigFile(t *testing.T) {
+	cfg, err := settings.Parse("config.yaml", []byte("stale: mark\n"))
+	require.NoError(t, err)
+	existing := []byte(`version: 2
+updates:
+  - package-ecosystem: terraform
+    directory: test_path/projectb
+    schedule:
+      interval: daily
+    reviewers: [platform] # added by hand
+    vendor: true
+  - package-ecosystem: terraform
+    directory: test_path/removed
+    schedule:
+      interval: weekly
+  - package-ecosystem: github-actions
+    directory: /
+    schedule:
+      interval: weekly
+`)
+
+	bot := New(WithKind("terraform"), WithRootPath("dependabot/test_path/"), WithSettings(cfg))
+	packages, out, err := bot.MergeConfigFile("./test_path/", existing)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"terraform"}, packages)
+	assert.Contains(t, out, "    directory: test_path/projectb\n    schedule:\n      interval: weekly\n      day: sunday\n    reviewers: [platform] # added by hand\n    vendor: true\n")
+	assert.Contains(t, out, "  # dependabot-templater: stale\n  - package-ecosystem: terraform\n    directory: test_path/removed\n")
+	// Only terraform was generated, the github-actions entry is not stale.
+	assert.Contains(t, out, "\n  - package-ecosystem: github-actions\n    directory: /\n")
+
+	cfg, err = settings.Parse("config.yaml", []byte("{}\n"))
+	require.NoError(t, err)
+	_, out, err = New(WithKind("terraform"), WithRootPath("dependabot/test_path/"), WithSettings(cfg)).MergeConfigFile("./test_path/", existing)
+	require.NoError(t, err)
+	assert.NotContains(t, out, "test_path/removed")
+	assert.Contains(t, out, "  - package-ecosystem: github-actions\n")
+
+	// The settings set reviewers, projectb has none of them.
+	cfg, err = settings.Parse("config.yaml", []byte("directories:\n  - path: test_path/other\n    reviewers: [octocat]\n"))
+	require.NoError(t, err)
+	_, out, err = New(WithKind("terraform"), WithRootPath("dependabot/test_path/"), WithSettings(cfg)).MergeConfigFile("./test_path/", existing)
+	require.NoError(t, err)
+	assert.NotContains(t, out, "reviewers")
+	assert.Contains(t, out, "    vendor: true\n")
+}
+
+func TestCheckConfigFile(t *testing.T) {
+	bot := New(WithKind("terraform"), WithRootPath("dependabot/test_path/"))
+	_, committed, err := bot.GenerateConfigFile("./test_path/")
+	require.NoError(t, err)
+
+	drift, err := bot.CheckConfigFile("./test_path/", "dependabot.yml", []byte(committed))
+	require.NoError(t, err)
+	assert.False(t, drift.HasDrift())
+
+	drift, err = New(WithKind("terraform,go"), WithRootPath("dependabot/test_path/")).CheckConfigFile("./test_path/", "dependabot.yml", []byte(committed))
+	require.NoError(t, err)
+	assert.True(t, drift.HasDrift())
+	assert.Equal(t, []config.Key{{Ecosystem: "gomod", Directory: "test_path/projectgo"}}, drift.Missing)
+	assert.Empty(t, drift.Extra)
+
+	drift, err = bot.CheckConfigFile("./test_path/", "dependabot.yml", nil)
+	require.NoError(t, err)
+	assert.Equal(t, []config.Key{{Ecosystem: "terraform", Directory: "test_path/projectb"}}, drift.Missing)
+}
+
+func TestDetect(t *testing.T) {
+	cfg, err := settings.Parse("config.yaml", []byte(`exclude: ["test_path/projectf"]`))
+	require.NoError(t, err)
+
+	bot := New(WithKind("python,maven,nuget"), WithRootPath("dependabot/test_path/"), WithSettings(cfg))
+	detections, err := bot.Detect("./test_path/")
+	require.NoError(t, err)
+	assert.Equal(t, []Detection{
+		{
+			Kind:      "python",
+			Ecosystem: "pip",
+			Folders:   []string{"test_path/projecte"},
+			Files:     []string{"test_path/projecte/requirements.txt"},
+		},
+		{
+			Kind:      "maven",
+			Ecosystem: "maven",
+			Folders:   []string{"test_path/projecti"},
+			Files:     []string{"test_path/projecti/pom.xml"},
+		},
+		{
+			Kind:      "nuget",
+			Ecosystem: "nuget",
+			Folders:   []string{"test_path/projectdotnet", "test_path/projectfsharp"},
+			// The projects below the solution are updated with it.
+			Files: []string{
+				"test_path/projectdotnet/Service.sln",
+				"test_path/projectdotnet/global.json",
+				"test_path/projectfsharp/Lib.fsproj",
+				"test_path/projectfsharp/packages.config",
+			},
+		},
+	}, detections)
+
+	// Folders moved by the normalizer keep their files.
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, ".github", "workflows"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ".github", "workflows", "ci.yml"), nil, 0o644))
+	t.Chdir(dir)
+	detections, err = New(WithKind("gha")).Detect(".")
+	require.NoError(t, err)
+	require.Len(t, detections, 1)
+	assert.Equal(t, []string{"/"}, detections[0].Folders)
+	assert.Len(t, detections[0].Files, 1)
+}
+
+func TestExplain(t *testing.T) {
+	cfg, err := settings.Parse("config.yaml", []byte(`exclude: ["test_path/projectf"]`))
+	require.NoError(t, err)
+
+	bot := New(WithKind("cargo,python"), WithRootPath("dependabot/test_path/"), WithSettings(cfg))
+	explanations, err := bot.Explain("./test_path/", "test_path/projectrust/crates/core")
+	require.NoError(t, err)
+	assert.Equal(t, []Explanation{
+		{
+			Kind: "cargo",
+			Matches: []Match{{
+				File: "test_path/projectrust/crates/core/Cargo.toml",
+				Rule: "all of (file name is one of [Cargo.toml]; not (path matches one of [target/**/*]))",
+			}},
+			Reason: "removed by normalizeCargo: workspace members are updated with the workspace root",
+		},
+		{
+			Kind:   "python",
+			Reason: "no file matches file name is one of [requirements.txt pyproject.toml]",
+		},
+	}, explanations)
+
+	explanations, err = bot.Explain("./test_path/", "test_path/projectf")
+	require.NoError(t, err)
+	assert.False(t, explanations[1].Generated)
+	assert.Equal(t, `excluded by the settings: matched by exclude "test_path/projectf"`, explanations[1].Reason)
+
+	explanations, err = bot.Explain("./test_path/", "./test_path/projectrust/")
+	require.NoError(t, err)
+	assert.True(t, explanations[0].Generated)
+	assert.Len(t, explanations[0].Matches, 1)
 
-	folders = normalizeFolders(folders)
+	explanations, err = bot.Explain("./test_path/", "test_path/projectrust/target/package/core")
+	require.NoError(t, err)
+	assert.Equal(t, "no file matches all of (file name is one of [Cargo.toml]; not (path matches one of [target/**/*]))", explanations[0].Reason)
 
-	assert.Equal(t, []string{"test_path/projecta",
-		"test_path/projectb",
-		"test_path/projectc",
-		"test_path/projectd"}, folders)
+	explanations, err = bot.Explain("./test_path/", "web/node_modules/left-pad")
+	require.NoError(t, err)
+	assert.Equal(t, "node_modules folders are never scanned", explanations[0].Reason)
 }
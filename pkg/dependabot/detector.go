@@ -0,0 +1,254 @@
+package dependabot
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/containifyci/dependabot-templater/pkg/search"
+)
+
+// Detector finds the folders of a single package ecosystem.
+type Detector interface {
+	// Kind is the name used to select the detector, e.g. "gha".
+	Kind() string
+	// Ecosystem is the Dependabot package-ecosystem value, e.g. "github-actions".
+	Ecosystem() string
+	Matcher() search.Matcher
+	// Template is the name of the template used to render the found folders.
+	Template() string
+	// Registry is the name of the registry referenced by default, if any. It is
+	// only referenced when the settings define a registry with that name.
+	Registry() string
+}
+
+// Normalizer is implemented by detectors that post-process the search result
+// before the folders are rendered.
+type Normalizer interface {
+	Normalize(result *search.Result) []string
+}
+
+// EcosystemChecker is implemented by detectors that decide whether their
+// ecosystem may be unknown to the validator, see AllowUnknownEcosystem.
+type EcosystemChecker interface {
+	AllowsUnknownEcosystem() bool
+}
+
+// NormalizeDescriber is implemented by normalizers that describe what they
+// change, it is shown when explaining removed folders.
+type NormalizeDescriber interface {
+	NormalizeDescription() string
+}
+
+var (
+	detectorsMu sync.RWMutex
+	detectors   []Detector
+)
+
+// Register adds a detector to the ones enumerated by WithKind("all").
+// A detector with the same kind as an existing one replaces it.
+func Register(detector Detector) {
+	detectorsMu.Lock()
+	defer detectorsMu.Unlock()
+	for i, d := range detectors {
+		if d.Kind() == detector.Kind() {
+			detectors[i] = detector
+			return
+		}
+	}
+	detectors = append(detectors, detector)
+}
+
+// Unregister removes the detector of kind, e.g. one added by a test.
+func Unregister(kind string) {
+	detectorsMu.Lock()
+	defer detectorsMu.Unlock()
+	detectors = slices.DeleteFunc(detectors, func(d Detector) bool { return d.Kind() == kind })
+}
+
+// Detectors returns all registered detectors in registration order.
+func Detectors() []Detector {
+	detectorsMu.RLock()
+	defer detectorsMu.RUnlock()
+	return append([]Detector(nil), detectors...)
+}
+
+// Kinds returns the kinds of all registered detectors in registration order.
+func Kinds() []string {
+	detectors := Detectors()
+	kinds := make([]string, len(detectors))
+	for i, d := range detectors {
+		kinds[i] = d.Kind()
+	}
+	return kinds
+}
+
+func Lookup(kind string) (Detector, bool) {
+	detectorsMu.RLock()
+	defer detectorsMu.RUnlock()
+	for _, d := range detectors {
+		if d.Kind() == kind {
+			return d, true
+		}
+	}
+	return nil, false
+}
+
+func lookup(kind string) (Detector, error) {
+	d, ok := Lookup(kind)
+	if !ok {
+		return nil, fmt.Errorf("unknown kind %q", kind)
+	}
+	return d, nil
+}
+
+type detector struct {
+	kind      string
+	ecosystem string
+	matcher   search.Matcher
+	template  string
+	registry  string
+	normalize func(*search.Result) []string
+	// normalizeDesc describes normalize for explanations.
+	normalizeDesc string
+	// unknownEcosystem allows an ecosystem the validator doesn't know.
+	unknownEcosystem bool
+}
+
+type DetectorOption func(*detector)
+
+// WithDefaultRegistry sets the registry the detector references by default.
+func WithDefaultRegistry(registry string) DetectorOption {
+	return func(d *detector) {
+		d.registry = registry
+	}
+}
+
+// WithNormalize sets the function used to post-process the search result.
+func WithNormalize(normalize func(*search.Result) []string) DetectorOption {
+	return func(d *detector) {
+		d.normalize = normalize
+	}
+}
+
+// WithNormalizeDescription describes the normalization for explanations.
+func WithNormalizeDescription(desc string) DetectorOption {
+	return func(d *detector) {
+		d.normalizeDesc = desc
+	}
+}
+
+// AllowUnknownEcosystem reports an ecosystem the validator doesn't know, e.g.
+// one Dependabot added after this version, as a warning instead of failing the
+// kind. A typo in a known ecosystem still fails.
+func AllowUnknownEcosystem() DetectorOption {
+	return func(d *detector) {
+		d.unknownEcosystem = true
+	}
+}
+
+// NewDetector returns a Detector rendering the folders found by matcher with tmpl.
+func NewDetector(kind, ecosystem string, matcher search.Matcher, tmpl string, opts ...DetectorOption) Detector {
+	d := &detector{
+		kind:      kind,
+		ecosystem: ecosystem,
+		matcher:   matcher,
+		template:  tmpl,
+	}
+	for _, opt := range opts {
+		opt(d)
+	}
+	return d
+}
+
+func (d *detector) Kind() string            { return d.kind }
+func (d *detector) Ecosystem() string       { return d.ecosystem }
+func (d *detector) Matcher() search.Matcher { return d.matcher }
+func (d *detector) Template() string        { return d.template }
+func (d *detector) Registry() string        { return d.registry }
+
+func (d *detector) AllowsUnknownEcosystem() bool { return d.unknownEcosystem }
+
+func (d *detector) NormalizeDescription() string {
+	if d.normalizeDesc == "" && d.normalize != nil {
+		return "custom normalization of " + d.kind
+	}
+	return d.normalizeDesc
+}
+
+func (d *detector) Normalize(result *search.Result) []string {
+	if d.normalize == nil {
+		return result.Folders
+	}
+	return d.normalize(result)
+}
+
+func init() {
+	Register(NewDetector("gha", "github-actions",
+		search.Or(search.Files("action.yml", "action.yaml"), search.Folder(".github/workflows")),
+		"dependabot-github-actions.yml.tmpl",
+		WithNormalize(folders(normalizeGithubActions)),
+		WithNormalizeDescription("normalizeGithubActions: workflows in .github/workflows are updated from the repository root /")))
+	Register(NewDetector("docker", "docker", search.Files("Dockerfile"), "dependabot-docker.yml.tmpl"))
+	Register(NewDetector("terraform", "terraform", search.Content(".tf", "backend"), "dependabot-terraform.yml.tmpl"))
+	Register(NewDetector("go", "gomod", search.Files("go.mod"), "dependabot-go.yml.tmpl"))
+	Register(NewDetector("gradle", "gradle", search.Files("build.gradle.kts", "build.gradle"), "dependabot-gradle.yml.tmpl"))
+	Register(NewDetector("maven", "maven", search.Files("pom.xml"), "dependabot-maven.yml.tmpl"))
+	Register(NewDetector("npm", "npm", search.Files("package.json"), "dependabot-npm.yml.tmpl",
+		WithNormalize(folders(normalizeNPM)),
+		WithNormalizeDescription("normalizeNPM: packages inside node_modules are dropped")))
+	Register(NewDetector("python", "pip", search.Files("requirements.txt", "pyproject.toml"), "dependabot-python.yml.tmpl"))
+	Register(NewDetector("cargo", "cargo", notUnder(search.Files("Cargo.toml"), "target"), "dependabot-cargo.yml.tmpl",
+		WithNormalize(normalizeCargo),
+		WithNormalizeDescription("normalizeCargo: workspace members are updated with the workspace root")))
+	Register(NewDetector("composer", "composer", notUnder(search.Files("composer.json"), "vendor", "deps"), "dependabot-composer.yml.tmpl"))
+	Register(NewDetector("bundler", "bundler",
+		notUnder(search.Or(search.Files("Gemfile"), search.Suffix(".gemspec")), "vendor", "deps"),
+		"dependabot-bundler.yml.tmpl"))
+	Register(NewDetector("mix", "mix", notUnder(search.Files("mix.exs"), "vendor", "deps"), "dependabot-mix.yml.tmpl",
+		WithNormalize(normalizeMix),
+		WithNormalizeDescription("normalizeMix: umbrella apps are updated with the umbrella root")))
+	Register(NewDetector("nuget", "nuget",
+		search.Or(search.Suffix(nugetProjectSuffixes...), search.Suffix(nugetSolutionSuffixes...),
+			search.Files("packages.config", nugetCentralPackages, "global.json")),
+		"dependabot-nuget.yml.tmpl",
+		WithNormalize(normalizeNuGet),
+		WithNormalizeDescription("normalizeNuGet: projects are updated with their solution or Directory.Packages.props root")))
+}
+
+func folders(normalize func([]string) []string) func(*search.Result) []string {
+	return func(result *search.Result) []string {
+		return normalize(result.Folders)
+	}
+}
+
+// notUnder excludes files below any directory with one of the given names.
+func notUnder(matcher search.Matcher, dirs ...string) search.Matcher {
+	patterns := make([]string, len(dirs))
+	for i, dir := range dirs {
+		patterns[i] = dir + "/**/*"
+	}
+	return search.And(matcher, search.Not(search.Path(patterns...)))
+}
+
+func normalizeGithubActions(folders []string) []string {
+	for i, folder := range folders {
+		if folder == ".github/workflows" {
+			folders[i] = "/"
+		}
+	}
+	return folders
+}
+
+func normalizeNPM(folders []string) []string {
+	validFolder := make([]string, len(folders))
+	counter := 0
+	for _, folder := range folders {
+		if !strings.Contains(folder, "/node_modules/") {
+			validFolder[counter] = folder
+			counter++
+		}
+	}
+	return validFolder[:counter]
+}
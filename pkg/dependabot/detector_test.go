@@ -0,0 +1,96 @@
+package dependabot
+
+import (
+	"testing"
+
+	"github.com/containifyci/dependabot-templater/pkg/search"
+	"github.com/containifyci/dependabot-templater/pkg/template"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegisterDetector(t *testing.T) {
+	template.Register("dependabot-custom.yml.tmpl", `{{- range . }}
+  - package-ecosystem: "custom"
+    directory: "{{ .Directory -}}"
+    {{- template "schedule" . }}
+{{- end -}}`)
+	Register(NewDetector("custom", "custom", search.Files("test.txt"), "dependabot-custom.yml.tmpl", AllowUnknownEcosystem()))
+	t.Cleanup(func() {
+		Unregister("custom")
+		template.Unregister("dependabot-custom.yml.tmpl")
+	})
+
+	assert.Contains(t, Kinds(), "custom")
+	detector, ok := Lookup("custom")
+	require.True(t, ok)
+	assert.Equal(t, "custom", detector.Ecosystem())
+
+	bot := New(WithKind("all"))
+	assert.Contains(t, bot.kinds, "custom")
+
+	var warnings []string
+	bot = New(WithKind("custom"), WithRootPath("dependabot/test_path/"), WithBackend(BackendTemplate), WithWarnings(func(w Warning) {
+		warnings = append(warnings, w.String())
+	}))
+	packages, config, err := bot.GenerateConfigFile("./test_path/")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"custom"}, packages)
+	assert.Contains(t, config, `directory: "test_path/projecta"`)
+	assert.Contains(t, warnings, `custom in test_path/projecta: unknown package-ecosystem "custom"`)
+}
+
+func TestRegisterDetectorUnknownEcosystem(t *testing.T) {
+	template.Register("dependabot-typo.yml.tmpl", `{{- range . }}
+  - package-ecosystem: "gomdo"
+    directory: "{{ .Directory -}}"
+    {{- template "schedule" . }}
+{{- end -}}`)
+	Register(NewDetector("typo", "gomdo", search.Files("test.txt"), "dependabot-typo.yml.tmpl"))
+	t.Cleanup(func() {
+		Unregister("typo")
+		template.Unregister("dependabot-typo.yml.tmpl")
+	})
+
+	var warnings []Warning
+	bot := New(WithKind("typo"), WithRootPath("dependabot/test_path/"), WithBackend(BackendTemplate), WithWarnings(func(w Warning) {
+		warnings = append(warnings, w)
+	}))
+	_, _, err := bot.GenerateConfigFile("./test_path/")
+	var genErr *GenerateError
+	require.ErrorAs(t, err, &genErr)
+	assert.Equal(t, []string{"typo"}, genErr.Kinds(StageValidate))
+	assert.Contains(t, err.Error(), `unknown package-ecosystem "gomdo"`)
+	assert.Empty(t, warnings)
+}
+
+func TestUnregisterDetector(t *testing.T) {
+	Register(NewDetector("scratch", "scratch", search.Files("scratch.txt"), "dependabot-scratch.yml.tmpl"))
+	Unregister("scratch")
+	_, ok := Lookup("scratch")
+	assert.False(t, ok)
+	assert.NotContains(t, Kinds(), "scratch")
+}
+
+func TestBuiltinDetectors(t *testing.T) {
+	for _, kind := range []string{"gha", "docker", "terraform", "go", "gradle", "maven", "npm", "python"} {
+		detector, ok := Lookup(kind)
+		require.True(t, ok, kind)
+		assert.NotEmpty(t, detector.Ecosystem())
+		assert.NotEmpty(t, detector.Template())
+	}
+}
+
+func TestUnknownKind(t *testing.T) {
+	bot := New(WithKind("unknown,terraform"), WithRootPath("dependabot/test_path/"))
+	packages, _, err := bot.GenerateConfigFile("./test_path/")
+
+	var genErr *GenerateError
+	require.ErrorAs(t, err, &genErr)
+	assert.Equal(t, []string{"unknown"}, genErr.Kinds(StageSearch))
+	assert.Equal(t, []string{"terraform"}, packages)
+
+	_, err = bot.Search("./test_path/", "unknown")
+	assert.Error(t, err)
+}
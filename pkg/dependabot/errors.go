@@ -0,0 +1,98 @@
+package dependabot
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	StageSearch = "search"
+	StageRender = "render"
+	StageHeader = "header"
+	// StageValidate reports problems of the generated config, see config.Validate.
+	StageValidate = "validate"
+	StageMerge    = "merge"
+	StageCheck    = "check"
+	// StageCodeOwners reports a CODEOWNERS file that can't be read.
+	StageCodeOwners = "codeowners"
+	// StageSettings reports settings that refer to kinds that aren't
+	// registered, see settings.CheckKinds.
+	StageSettings = "settings"
+)
+
+// Warning is a problem that doesn't prevent generating the config, see
+// WithWarnings.
+type Warning struct {
+	Kind      string
+	Directory string
+	Msg       string
+}
+
+func (w Warning) String() string {
+	if w.Kind == "" {
+		return w.Msg
+	}
+	return fmt.Sprintf("%s in %s: %s", w.Kind, w.Directory, w.Msg)
+}
+
+// KindError describes a failure of a single ecosystem while generating the config.
+type KindError struct {
+	Kind  string
+	Path  string
+	Stage string
+	Err   error
+}
+
+func (e *KindError) Error() string {
+	if e.Kind == "" {
+		return fmt.Sprintf("%s %s: %s", e.Stage, e.Path, e.Err)
+	}
+	return fmt.Sprintf("%s %s in %s: %s", e.Stage, e.Kind, e.Path, e.Err)
+}
+
+func (e *KindError) Unwrap() error {
+	return e.Err
+}
+
+// GenerateError collects every KindError of a single generation run.
+type GenerateError struct {
+	Errors []*KindError
+}
+
+func (e *GenerateError) Error() string {
+	msgs := make([]string, len(e.Errors))
+	for i, err := range e.Errors {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "\n")
+}
+
+func (e *GenerateError) Unwrap() []error {
+	errs := make([]error, len(e.Errors))
+	for i, err := range e.Errors {
+		errs[i] = err
+	}
+	return errs
+}
+
+// Kinds returns the kinds that failed in the given stage.
+func (e *GenerateError) Kinds(stage string) []string {
+	var kinds []string
+	for _, err := range e.Errors {
+		if err.Stage == stage && err.Kind != "" {
+			kinds = append(kinds, err.Kind)
+		}
+	}
+	return kinds
+}
+
+func (e *GenerateError) add(kind, path, stage string, err error) {
+	e.Errors = append(e.Errors, &KindError{Kind: kind, Path: path, Stage: stage, Err: err})
+}
+
+func (e *GenerateError) errOrNil() error {
+	if len(e.Errors) == 0 {
+		return nil
+	}
+	return e
+}
@@ -0,0 +1,117 @@
+package dependabot
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/containifyci/dependabot-templater/pkg/search"
+)
+
+// Explanation tells why an entry of a kind is or isn't generated for a directory.
+type Explanation struct {
+	Kind string `json:"kind" yaml:"kind"`
+	// Generated reports whether an entry is generated for the directory.
+	Generated bool `json:"generated" yaml:"generated"`
+	// Matches are the files of the directory matched by the detector.
+	Matches []Match `json:"matches,omitempty" yaml:"matches,omitempty"`
+	// Reason explains a missing entry or an entry generated by normalization.
+	Reason string `json:"reason,omitempty" yaml:"reason,omitempty"`
+}
+
+// Match is a file matched by a detector with the rule that matched it.
+type Match struct {
+	File string `json:"file" yaml:"file"`
+	Rule string `json:"rule" yaml:"rule"`
+}
+
+// Explain returns for every kind why dir, a directory as it appears in the
+// generated config, does or doesn't get an entry.
+func (d *DependaBot) Explain(path, dir string) ([]Explanation, error) {
+	dir = filepath.ToSlash(filepath.Clean(dir))
+	kinds := make([]string, 0, len(d.kinds))
+	for _, kind := range d.kinds {
+		if _, err := lookup(kind); err != nil {
+			return nil, err
+		}
+		kinds = append(kinds, kind)
+	}
+	found, err := d.walk(path, kinds)
+	if err != nil {
+		return nil, err
+	}
+
+	explanations := make([]Explanation, 0, len(kinds))
+	for _, kind := range kinds {
+		detector, _ := Lookup(kind)
+		explanations = append(explanations, d.explain(detector, found[kind], path, dir))
+	}
+	return explanations, nil
+}
+
+func (d *DependaBot) explain(detector Detector, result *search.Result, root, dir string) Explanation {
+	e := Explanation{Kind: detector.Kind()}
+	for _, file := range result.Files {
+		if d.folder(search.NormalizePath(file)) != dir {
+			continue
+		}
+		rule := search.Describe(detector.Matcher())
+		if info, err := os.Lstat(file); err == nil {
+			rel, entry := search.Rel(root, file, fs.FileInfoToDirEntry(info))
+			rule, _ = search.Explain(detector.Matcher(), rel, entry)
+		}
+		e.Matches = append(e.Matches, Match{File: filepath.ToSlash(file), Rule: rule})
+	}
+
+	found := slices.ContainsFunc(result.Folders, func(folder string) bool { return d.folder(folder) == dir })
+	normalized := found
+	normalizeDesc := ""
+	if normalizer, ok := detector.(Normalizer); ok {
+		// Normalizers may modify the folders in place.
+		folders := normalizer.Normalize(&search.Result{Folders: slices.Clone(result.Folders), Files: result.Files})
+		normalized = slices.ContainsFunc(folders, func(folder string) bool { return d.folder(folder) == dir })
+		if describer, ok := normalizer.(NormalizeDescriber); ok {
+			normalizeDesc = describer.NormalizeDescription()
+		}
+	}
+
+	switch {
+	case !found && !normalized:
+		e.Reason = d.noMatch(detector, dir)
+	case found && !normalized:
+		e.Reason = "removed by " + normalizeDesc
+	case !d.settings.Included(dir):
+		e.Reason = "excluded by the settings: " + d.settings.Exclusion(dir)
+	default:
+		e.Generated = true
+		if !found {
+			e.Reason = "added by " + normalizeDesc
+		}
+	}
+	return e
+}
+
+// folder converts a found folder to the directory of the generated config.
+func (d *DependaBot) folder(folder string) string {
+	return replacePrefix(folder, d.rootPath, ".")
+}
+
+// noMatch explains why no file of dir matched.
+func (d *DependaBot) noMatch(detector Detector, dir string) string {
+	for _, segment := range strings.Split(dir, "/") {
+		if slices.Contains(search.SkipDirs, segment) {
+			return fmt.Sprintf("%s folders are never scanned", segment)
+		}
+	}
+	reason := "no file matches " + search.Describe(detector.Matcher())
+	switch d.scanMode {
+	case search.ModeGitignore:
+		reason += ", files ignored by git are not scanned"
+	case search.ModeGitIndex:
+		reason += ", only files in the git index are scanned"
+	}
+	return reason
+}
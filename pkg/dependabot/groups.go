@@ -0,0 +1,144 @@
+package dependabot
+
+import (
+	"maps"
+	"slices"
+
+	"github.com/containifyci/dependabot-templater/pkg/config"
+	"github.com/containifyci/dependabot-templater/pkg/settings"
+	"github.com/containifyci/dependabot-templater/pkg/template"
+)
+
+// scopes are the dependency families of the per-scope preset, named after
+// the conventions of each ecosystem.
+var scopes = map[string]map[string][]string{
+	"npm": {
+		"aws-sdk":   {"@aws-sdk/*"},
+		"types":     {"@types/*"},
+		"babel":     {"@babel/*"},
+		"eslint":    {"eslint", "eslint-*", "@eslint/*", "@typescript-eslint/*"},
+		"angular":   {"@angular/*"},
+		"storybook": {"storybook", "@storybook/*"},
+	},
+	"gomod": {
+		"aws-sdk":       {"github.com/aws/aws-sdk-go-v2", "github.com/aws/aws-sdk-go-v2/*"},
+		"golang-x":      {"golang.org/x/*"},
+		"opentelemetry": {"go.opentelemetry.io/*"},
+		"kubernetes":    {"k8s.io/*", "sigs.k8s.io/*"},
+	},
+	"maven": {
+		"aws-sdk": {"software.amazon.awssdk:*"},
+		"spring":  {"org.springframework*"},
+		"jackson": {"com.fasterxml.jackson*"},
+	},
+	"pip": {
+		"aws-sdk": {"boto3", "botocore", "boto3-stubs*", "aiobotocore"},
+		"pytest":  {"pytest", "pytest-*"},
+	},
+	"nuget": {
+		"aws-sdk":   {"AWSSDK.*"},
+		"microsoft": {"Microsoft.*", "System.*"},
+	},
+	"github-actions": {
+		"github": {"actions/*", "github/*"},
+	},
+	"cargo": {
+		"aws-sdk": {"aws-sdk-*", "aws-config", "aws-smithy-*"},
+		"tokio":   {"tokio", "tokio-*"},
+	},
+	"bundler": {
+		"aws-sdk": {"aws-sdk-*"},
+		"rails":   {"rails", "active*", "action*"},
+	},
+	"composer": {
+		"aws-sdk": {"aws/*"},
+		"symfony": {"symfony/*"},
+		"laravel": {"laravel/*", "illuminate/*"},
+	},
+}
+
+func init() {
+	// Gradle resolves the same maven coordinates.
+	scopes["gradle"] = scopes["maven"]
+}
+
+// presetGroups returns the groups of a preset for the ecosystem.
+func presetGroups(preset, ecosystem string) map[string]config.Group {
+	minorAndPatch := []string{"minor", "patch"}
+	switch preset {
+	case "minor":
+		return map[string]config.Group{"minor": {Patterns: []string{"*"}, UpdateTypes: minorAndPatch}}
+	case "all-in-one":
+		return map[string]config.Group{"all": {Patterns: []string{"*"}}}
+	case "split-major":
+		return map[string]config.Group{
+			"minor-and-patch": {Patterns: []string{"*"}, UpdateTypes: minorAndPatch},
+			"major":           {Patterns: []string{"*"}, UpdateTypes: []string{"major"}},
+		}
+	case "per-scope":
+		// Dependabot puts a dependency into the first matching group, the
+		// others group excludes the scopes so the order doesn't matter.
+		groups := map[string]config.Group{}
+		var scoped []string
+		for _, name := range slices.Sorted(maps.Keys(scopes[ecosystem])) {
+			groups[name] = config.Group{Patterns: scopes[ecosystem][name]}
+			scoped = append(scoped, scopes[ecosystem][name]...)
+		}
+		groups["others"] = config.Group{Patterns: []string{"*"}, ExcludePatterns: scoped}
+		return groups
+	default:
+		return nil
+	}
+}
+
+// resolveGroups returns the groups of the preset with the groups of the
+// options added, sorted by name. Dependabot puts a dependency into the first
+// matching group and the config lists the groups by name, so the preset groups
+// exclude the patterns of the version update groups of the options.
+func resolveGroups(ecosystem string, opts settings.Options) []template.Group {
+	preset := opts.GroupPreset
+	if preset == "" {
+		preset = "minor"
+		if ecosystem == "terraform" {
+			preset = "none"
+		}
+	}
+	groups := presetGroups(preset, ecosystem)
+	if groups == nil {
+		groups = map[string]config.Group{}
+	}
+	var patterns []string
+	for _, name := range slices.Sorted(maps.Keys(opts.Groups)) {
+		if g := opts.Groups[name]; g.AppliesTo == "" || g.AppliesTo == "version-updates" {
+			patterns = append(patterns, g.Patterns...)
+		}
+	}
+	for name, g := range groups {
+		if _, ok := opts.Groups[name]; ok || len(patterns) == 0 {
+			continue
+		}
+		excluded := slices.Clone(g.ExcludePatterns)
+		for _, p := range patterns {
+			if !slices.Contains(excluded, p) {
+				excluded = append(excluded, p)
+			}
+		}
+		g.ExcludePatterns = excluded
+		groups[name] = g
+	}
+	maps.Copy(groups, opts.Groups)
+
+	resolved := make([]template.Group, 0, len(groups))
+	for _, name := range slices.Sorted(maps.Keys(groups)) {
+		g := groups[name]
+		resolved = append(resolved, template.Group{
+			Name:            name,
+			AppliesTo:       g.AppliesTo,
+			DependencyType:  g.DependencyType,
+			Patterns:        g.Patterns,
+			ExcludePatterns: g.ExcludePatterns,
+			UpdateTypes:     g.UpdateTypes,
+		})
+	}
+	return resolved
+}
@@ -0,0 +1,90 @@
+package dependabot
+
+import (
+	"testing"
+
+	"github.com/containifyci/dependabot-templater/pkg/config"
+	"github.com/containifyci/dependabot-templater/pkg/settings"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPresetGroups(t *testing.T) {
+	for _, preset := range settings.GroupPresets {
+		groups := presetGroups(preset, "npm")
+		if preset == "none" {
+			assert.Empty(t, groups)
+			continue
+		}
+		assert.NotEmpty(t, groups, preset)
+		for name, g := range groups {
+			assert.Empty(t, g.Problems(name), preset)
+		}
+	}
+
+	groups := presetGroups("per-scope", "npm")
+	assert.Equal(t, config.Group{Patterns: []string{"@aws-sdk/*"}}, groups["aws-sdk"])
+	assert.Contains(t, groups["others"].ExcludePatterns, "@aws-sdk/*")
+	assert.Equal(t, map[string]config.Group{"others": {Patterns: []string{"*"}}}, presetGroups("per-scope", "docker"))
+}
+
+func TestGroups(t *testing.T) {
+	cfg, err := settings.Parse("config.yaml", []byte(`group-preset: split-major
+ecosystems:
+  npm:
+    group-preset: per-scope
+  terraform:
+    groups:
+      providers:
+        patterns: ["hashicorp/*"]
+directories:
+  - path: test_path/projecte
+    group-preset: none
+    groups:
+      security:
+        applies-to: security-updates
+        patterns: ["*"]
+      dev:
+        dependency-type: development
+        update-types: [minor, patch]
+`))
+	require.NoError(t, err)
+
+	updates := generateBoth(t, cfg, []string{"python", "npm", "terraform", "go"})
+	assert.Equal(t, map[string]config.Group{
+		"security": {AppliesTo: "security-updates", Patterns: []string{"*"}},
+		"dev":      {DependencyType: "development", UpdateTypes: []string{"minor", "patch"}},
+	}, updates[config.Key{Ecosystem: "pip", Directory: "test_path/projecte"}].Groups)
+	assert.Equal(t, presetGroups("split-major", "pip"), updates[config.Key{Ecosystem: "pip", Directory: "test_path/projectf"}].Groups)
+	assert.Contains(t, updates[config.Key{Ecosystem: "npm", Directory: "test_path/projectd"}].Groups, "types")
+
+	terraform := updates[config.Key{Ecosystem: "terraform", Directory: "test_path/projectb"}].Groups
+	assert.Equal(t, config.Group{Patterns: []string{"*"}, UpdateTypes: []string{"major"}, ExcludePatterns: []string{"hashicorp/*"}}, terraform["major"])
+	assert.Equal(t, config.Group{Patterns: []string{"hashicorp/*"}}, terraform["providers"])
+}
+
+func TestGroupsBeforePreset(t *testing.T) {
+	cfg, err := settings.Parse("config.yaml", []byte(`groups:
+  tools:
+    patterns: ["golang.org/x/*"]
+  security:
+    applies-to: security-updates
+    patterns: ["github.com/*"]
+ecosystems:
+  go:
+    group-preset: per-scope
+`))
+	require.NoError(t, err)
+
+	updates := generateBoth(t, cfg, []string{"python", "go"})
+	assert.Equal(t, map[string]config.Group{
+		"minor":    {Patterns: []string{"*"}, ExcludePatterns: []string{"golang.org/x/*"}, UpdateTypes: []string{"minor", "patch"}},
+		"tools":    {Patterns: []string{"golang.org/x/*"}},
+		"security": {AppliesTo: "security-updates", Patterns: []string{"github.com/*"}},
+	}, updates[config.Key{Ecosystem: "pip", Directory: "test_path/projecte"}].Groups)
+
+	gomod := updates[config.Key{Ecosystem: "gomod", Directory: "test_path/projectgo"}].Groups
+	assert.Equal(t, []string{"golang.org/x/*"}, gomod["golang-x"].ExcludePatterns)
+	assert.Equal(t, presetGroups("per-scope", "gomod")["others"], gomod["others"])
+}
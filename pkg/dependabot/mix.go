@@ -0,0 +1,44 @@
+package dependabot
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/containifyci/dependabot-templater/pkg/search"
+)
+
+var mixAppsPath = regexp.MustCompile(`apps_path:\s*"([^"]+)"`)
+
+// normalizeMix drops the apps of an umbrella project, Dependabot updates them
+// through the umbrella root.
+func normalizeMix(result *search.Result) []string {
+	var appsDirs []string
+	for _, file := range result.Files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			continue
+		}
+		if match := mixAppsPath.FindSubmatch(data); match != nil {
+			appsDirs = append(appsDirs, filepath.Join(search.NormalizePath(file), string(match[1])))
+		}
+	}
+
+	folders := make([]string, 0, len(result.Folders))
+	for _, folder := range result.Folders {
+		if !slices.ContainsFunc(appsDirs, func(dir string) bool { return isBelow(folder, dir) }) {
+			folders = append(folders, folder)
+		}
+	}
+	return folders
+}
+
+// isBelow reports whether folder is dir or one of its sub folders.
+func isBelow(folder, dir string) bool {
+	if dir == "." {
+		return true
+	}
+	return folder == dir || strings.HasPrefix(folder, dir+"/")
+}
@@ -0,0 +1,44 @@
+package dependabot
+
+import (
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/containifyci/dependabot-templater/pkg/search"
+)
+
+const nugetCentralPackages = "Directory.Packages.props"
+
+var (
+	nugetProjectSuffixes  = []string{".csproj", ".fsproj", ".vbproj"}
+	nugetSolutionSuffixes = []string{".sln", ".slnx"}
+)
+
+// normalizeNuGet collapses the projects below a solution or a central package
+// management file into the directory of that file.
+func normalizeNuGet(result *search.Result) []string {
+	var roots []string
+	for _, file := range result.Files {
+		if isNuGetRoot(filepath.Base(file)) {
+			roots = append(roots, search.NormalizePath(file))
+		}
+	}
+
+	folders := make([]string, 0, len(result.Folders))
+	for _, folder := range result.Folders {
+		if !slices.ContainsFunc(roots, func(root string) bool { return folder != root && isBelow(folder, root) }) {
+			folders = append(folders, folder)
+		}
+	}
+	return folders
+}
+
+func isNuGetRoot(name string) bool {
+	if strings.EqualFold(name, nugetCentralPackages) {
+		return true
+	}
+	return slices.ContainsFunc(nugetSolutionSuffixes, func(suffix string) bool {
+		return strings.HasSuffix(strings.ToLower(name), suffix)
+	})
+}
@@ -0,0 +1,90 @@
+package dependabot
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/containifyci/dependabot-templater/pkg/codeowners"
+	"github.com/containifyci/dependabot-templater/pkg/template"
+)
+
+// teamLabelPrefix is the prefix of the labels added for owning teams.
+const teamLabelPrefix = "team:"
+
+// WithWarnings reports warnings like directories without owner to fn, they
+// are dropped by default.
+func WithWarnings(fn func(Warning)) Option {
+	return func(g *DependaBot) {
+		g.warn = fn
+	}
+}
+
+func (d *DependaBot) warning(w Warning) {
+	if d.warn != nil {
+		d.warn(w)
+	}
+}
+
+// codeOwners loads the CODEOWNERS file of the repository at path if the
+// settings use it.
+func (d *DependaBot) codeOwners(path string, errs *GenerateError) *codeowners.File {
+	if d.settings.CodeOwners == nil {
+		return nil
+	}
+	file := d.settings.CodeOwnersPath()
+	if file == "" {
+		file = codeowners.Find(path)
+	}
+	if file == "" {
+		d.warning(Warning{Msg: "no CODEOWNERS file found in " + path})
+		return nil
+	}
+	owners, err := codeowners.Load(file)
+	if err != nil {
+		errs.add("", file, StageCodeOwners, err)
+		return nil
+	}
+	return owners
+}
+
+// applyOwners adds the owners of the entry directory as configured in the
+// settings. Reviewers and assignees of the options are kept.
+func (d *DependaBot) applyOwners(kind string, entry *template.DependaBotEntry, owners *codeowners.File) {
+	owned := owners.Owners(entry.Directory, true)
+	if len(owned) == 0 {
+		d.warning(Warning{Kind: kind, Directory: entry.Directory, Msg: "directory has no owner in CODEOWNERS"})
+		return
+	}
+
+	var users, teams []string
+	for _, owner := range owned {
+		// Email addresses can't be mapped to GitHub users.
+		name, ok := strings.CutPrefix(owner, "@")
+		if !ok {
+			continue
+		}
+		if strings.Contains(name, "/") {
+			teams = append(teams, name)
+		} else {
+			users = append(users, name)
+		}
+	}
+
+	cfg := d.settings.CodeOwners
+	if cfg.Reviewers && entry.Reviewers == nil {
+		entry.Reviewers = append(slices.Clone(users), teams...)
+	}
+	if cfg.Assignees && entry.Assignees == nil {
+		entry.Assignees = users
+	}
+	if cfg.TeamLabels {
+		labels := slices.Clone(entry.Labels)
+		for _, team := range teams {
+			_, name, _ := strings.Cut(team, "/")
+			if label := teamLabelPrefix + name; !slices.Contains(labels, label) {
+				labels = append(labels, label)
+			}
+		}
+		entry.Labels = labels
+	}
+}
@@ -0,0 +1,59 @@
+package dependabot
+
+import (
+	"testing"
+
+	"github.com/containifyci/dependabot-templater/pkg/config"
+	"github.com/containifyci/dependabot-templater/pkg/settings"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCodeOwners(t *testing.T) {
+	cfg, err := settings.Parse("config.yaml", []byte(`kinds: [python, terraform]
+labels: [dependencies]
+codeowners:
+  file: testdata/CODEOWNERS
+  reviewers: true
+  assignees: true
+  team-labels: true
+ecosystems:
+  terraform:
+    reviewers: [infra-lead]
+`))
+	require.NoError(t, err)
+
+	var warnings []Warning
+	updates := generateBoth(t, cfg, []string{"python", "terraform"}, WithWarnings(func(w Warning) { warnings = append(warnings, w) }))
+
+	pip := updates[config.Key{Ecosystem: "pip", Directory: "test_path/projecte"}]
+	assert.Equal(t, []string{"alice", "acme/python-team"}, pip.Reviewers)
+	assert.Equal(t, []string{"alice"}, pip.Assignees)
+	assert.Equal(t, []string{"dependencies", "team:python-team"}, pip.Labels)
+
+	terraform := updates[config.Key{Ecosystem: "terraform", Directory: "test_path/projectb"}]
+	assert.Equal(t, []string{"infra-lead"}, terraform.Reviewers)
+	assert.Empty(t, terraform.Assignees)
+	assert.Equal(t, []string{"dependencies", "team:platform"}, terraform.Labels)
+
+	unowned := updates[config.Key{Ecosystem: "pip", Directory: "test_path/projectf"}]
+	assert.Empty(t, unowned.Reviewers)
+	assert.Equal(t, []Warning{{Kind: "python", Directory: "test_path/projectf", Msg: "directory has no owner in CODEOWNERS"}}, warnings)
+	assert.Equal(t, "python in test_path/projectf: directory has no owner in CODEOWNERS", warnings[0].String())
+}
+
+func TestCodeOwnersMissing(t *testing.T) {
+	var warnings []Warning
+	bot := New(WithKind("python"), WithSettings(&settings.Settings{CodeOwners: &settings.CodeOwners{Reviewers: true}}),
+		WithRootPath("dependabot/test_path/"), WithWarnings(func(w Warning) { warnings = append(warnings, w) }))
+	_, _, err := bot.Generate("./test_path/")
+	require.NoError(t, err)
+	assert.Equal(t, []Warning{{Msg: "no CODEOWNERS file found in ./test_path/"}}, warnings)
+
+	bot = New(WithKind("python"), WithSettings(&settings.Settings{CodeOwners: &settings.CodeOwners{File: "testdata/missing"}}))
+	_, _, err = bot.Generate("./test_path/")
+	var genErr *GenerateError
+	require.ErrorAs(t, err, &genErr)
+	assert.Equal(t, StageCodeOwners, genErr.Errors[0].Stage)
+}
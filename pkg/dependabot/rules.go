@@ -0,0 +1,81 @@
+package dependabot
+
+import (
+	"slices"
+
+	"github.com/containifyci/dependabot-templater/pkg/config"
+	"github.com/containifyci/dependabot-templater/pkg/settings"
+	"github.com/containifyci/dependabot-templater/pkg/template"
+)
+
+// presetRules returns the ignore and allow rules of a preset for the
+// ecosystem, presets that don't apply to the ecosystem have no rules.
+func presetRules(preset, ecosystem string) ([]config.Ignore, []config.Allow) {
+	switch preset {
+	case "majors-only":
+		return []config.Ignore{{
+			DependencyName: "*",
+			UpdateTypes:    []string{"version-update:semver-patch", "version-update:semver-minor"},
+		}}, nil
+	case "ignore-go-toolchain":
+		switch ecosystem {
+		case "gomod":
+			return []config.Ignore{{DependencyName: "go"}, {DependencyName: "toolchain"}}, nil
+		case "docker":
+			// Go releases are 1.x, a minor update of the image is a new toolchain.
+			return []config.Ignore{{
+				DependencyName: "golang",
+				UpdateTypes:    []string{"version-update:semver-major", "version-update:semver-minor"},
+			}}, nil
+		}
+	case "ignore-types-majors":
+		if ecosystem == "npm" || ecosystem == "bun" {
+			return []config.Ignore{{DependencyName: "@types/*", UpdateTypes: []string{"version-update:semver-major"}}}, nil
+		}
+	}
+	return nil, nil
+}
+
+// rulePresets returns the presets of the options, terraform defaults to
+// majors-only.
+func rulePresets(ecosystem string, opts settings.Options) []string {
+	if opts.RulePresets == nil && ecosystem == "terraform" {
+		return []string{"majors-only"}
+	}
+	return opts.RulePresets
+}
+
+// resolveRules returns the rules of the presets followed by the rules of the
+// options.
+func resolveRules(ecosystem string, opts settings.Options) ([]template.Ignore, []template.Allow) {
+	var ignores []config.Ignore
+	var allows []config.Allow
+	for _, preset := range rulePresets(ecosystem, opts) {
+		ignore, allow := presetRules(preset, ecosystem)
+		ignores = append(ignores, ignore...)
+		allows = append(allows, allow...)
+	}
+	ignores = append(ignores, opts.Ignore...)
+	allows = append(allows, opts.Allow...)
+
+	var resolvedIgnores []template.Ignore
+	for _, i := range ignores {
+		resolvedIgnores = append(resolvedIgnores, template.Ignore{
+			DependencyName: i.DependencyName,
+			Versions:       i.Versions,
+			UpdateTypes:    i.UpdateTypes,
+		})
+	}
+	var resolvedAllows []template.Allow
+	for _, a := range allows {
+		resolvedAllows = append(resolvedAllows, template.Allow{DependencyName: a.DependencyName, DependencyType: a.DependencyType})
+	}
+	return resolvedIgnores, resolvedAllows
+}
+
+// securityOnly reports whether the security-only preset disables version
+// updates, it wins over open-pull-requests-limit as security updates are
+// not limited by it.
+func securityOnly(opts settings.Options) bool {
+	return slices.Contains(opts.RulePresets, "security-only")
+}
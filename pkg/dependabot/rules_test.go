@@ -0,0 +1,85 @@
+package dependabot
+
+import (
+	"testing"
+
+	"github.com/containifyci/dependabot-templater/pkg/config"
+	"github.com/containifyci/dependabot-templater/pkg/settings"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPresetRules(t *testing.T) {
+	for _, preset := range settings.RulePresets {
+		for _, ecosystem := range []string{"npm", "gomod", "docker", "terraform"} {
+			ignores, allows := presetRules(preset, ecosystem)
+			for _, i := range ignores {
+				assert.Empty(t, i.Problems(ecosystem), preset)
+			}
+			for _, a := range allows {
+				assert.Empty(t, a.Problems(), preset)
+			}
+		}
+	}
+
+	ignores, _ := presetRules("ignore-go-toolchain", "gomod")
+	assert.Equal(t, []config.Ignore{{DependencyName: "go"}, {DependencyName: "toolchain"}}, ignores)
+	ignores, _ = presetRules("ignore-types-majors", "gomod")
+	assert.Empty(t, ignores)
+}
+
+func TestRules(t *testing.T) {
+	cfg, err := settings.Parse("config.yaml", []byte(`rule-presets: [ignore-types-majors]
+ecosystems:
+  npm:
+    ignore:
+      - dependency-name: react
+        versions: [">=19.0.0 <20"]
+    allow:
+      - dependency-type: direct
+  terraform:
+    rule-presets: []
+directories:
+  - path: test_path/projecte
+    rule-presets: [security-only]
+    ignore:
+      - dependency-name: django
+        versions: [">=5.0,<6"]
+`))
+	require.NoError(t, err)
+
+	updates := generateBoth(t, cfg, []string{"python", "npm", "terraform"})
+	npm := updates[config.Key{Ecosystem: "npm", Directory: "test_path/projectd"}]
+	assert.Equal(t, []config.Ignore{
+		{DependencyName: "@types/*", UpdateTypes: []string{"version-update:semver-major"}},
+		{DependencyName: "react", Versions: []string{">=19.0.0 <20"}},
+	}, npm.Ignore)
+	assert.Equal(t, []config.Allow{{DependencyType: "direct"}}, npm.Allow)
+
+	pip := updates[config.Key{Ecosystem: "pip", Directory: "test_path/projecte"}]
+	assert.Equal(t, []config.Ignore{{DependencyName: "django", Versions: []string{">=5.0,<6"}}}, pip.Ignore)
+	assert.Equal(t, 0, *pip.OpenPullRequestsLimit)
+	assert.Nil(t, updates[config.Key{Ecosystem: "pip", Directory: "test_path/projectf"}].OpenPullRequestsLimit)
+
+	assert.Empty(t, updates[config.Key{Ecosystem: "terraform", Directory: "test_path/projectb"}].Ignore)
+}
+
+func TestRulesDefaultAndVersions(t *testing.T) {
+	_, generated, err := New(WithKind("terraform"), WithRootPath("dependabot/test_path/"), WithSettings(&settings.Settings{})).Generate("./test_path/")
+	require.NoError(t, err)
+	majorsOnly, _ := presetRules("majors-only", "terraform")
+	for _, u := range generated.Updates {
+		assert.Equal(t, majorsOnly, u.Ignore)
+	}
+
+	cfg, err := settings.Parse("config.yaml", []byte(`ignore:
+  - dependency-name: django
+    versions: ["^5.0"]
+`))
+	require.NoError(t, err)
+	_, _, err = New(WithKind("python"), WithRootPath("dependabot/test_path/"), WithSettings(cfg)).Generate("./test_path/")
+	var generateErr *GenerateError
+	require.ErrorAs(t, err, &generateErr)
+	assert.Contains(t, err.Error(), `invalid version range "^5.0", expected a PEP 440 specifier`)
+}
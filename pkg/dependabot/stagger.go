@@ -0,0 +1,85 @@
+package dependabot
+
+import (
+	"fmt"
+	"hash/fnv"
+	"slices"
+
+	"github.com/containifyci/dependabot-templater/pkg/config"
+	"github.com/containifyci/dependabot-templater/pkg/settings"
+)
+
+// defaultStaggerStep is the distance between two staggered times in minutes.
+const defaultStaggerStep = 30
+
+// WithExisting passes the committed config. Entries that are already
+// scheduled inside their stagger window keep their day and time, so changing
+// the window doesn't reshuffle them.
+func WithExisting(existing *config.Config) Option {
+	return func(g *DependaBot) {
+		g.existing = make(map[config.Key]config.Schedule, len(existing.Updates))
+		for _, u := range existing.Updates {
+			g.existing[u.Key()] = u.Schedule
+		}
+	}
+}
+
+// WithReshuffle ignores the schedules of the existing entries, every entry
+// moves to the slot of its hash.
+func WithReshuffle(reshuffle bool) Option {
+	return func(g *DependaBot) {
+		g.reshuffle = reshuffle
+	}
+}
+
+// stagger replaces the day and time of the schedule with the slot of the
+// entry inside the stagger window.
+func (d *DependaBot) stagger(key config.Key, schedule settings.Schedule) settings.Schedule {
+	st := schedule.Stagger
+	if st == nil || schedule.Interval == "cron" {
+		return schedule
+	}
+	existing, pinned := d.existing[key]
+	pinned = pinned && !d.reshuffle
+
+	h := fnv.New64a()
+	h.Write([]byte(key.Ecosystem + "\x00" + key.Directory))
+	sum := h.Sum64()
+
+	if len(st.Days) > 0 && schedule.Interval == "weekly" {
+		if pinned && slices.Contains(st.Days, existing.Day) {
+			schedule.Day = existing.Day
+		} else {
+			schedule.Day = st.Days[sum%uint64(len(st.Days))]
+		}
+		sum /= uint64(len(st.Days))
+	}
+	if st.From != "" && st.To != "" {
+		times := staggerTimes(st)
+		if pinned && existing.Time >= st.From && existing.Time <= st.To {
+			schedule.Time = existing.Time
+		} else {
+			schedule.Time = times[sum%uint64(len(times))]
+		}
+	}
+	return schedule
+}
+
+// staggerTimes lists the times from From to To every Step minutes.
+func staggerTimes(st *settings.Stagger) []string {
+	step := st.Step
+	if step <= 0 {
+		step = defaultStaggerStep
+	}
+	var times []string
+	for m := minutes(st.From); m <= minutes(st.To); m += step {
+		times = append(times, fmt.Sprintf("%02d:%02d", m/60, m%60))
+	}
+	return times
+}
+
+func minutes(hhmm string) int {
+	var h, m int
+	fmt.Sscanf(hhmm, "%d:%d", &h, &m)
+	return h*60 + m
+}
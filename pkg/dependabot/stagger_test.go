@@ -0,0 +1,61 @@
+package dependabot
+
+import (
+	"testing"
+
+	"github.com/containifyci/dependabot-templater/pkg/config"
+	"github.com/containifyci/dependabot-templater/pkg/settings"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStagger(t *testing.T) {
+	cfg, err := settings.Parse("config.yaml", []byte(`kinds: [go, python, npm, docker, cargo, nuget, terraform]
+schedule:
+  stagger:
+    days: [monday, tuesday, wednesday, thursday]
+    from: "06:00"
+    to: "09:00"
+`))
+	require.NoError(t, err)
+
+	generate := func(opts ...Option) map[config.Key]config.Schedule {
+		bot := New(append([]Option{WithSettings(cfg), WithRootPath("dependabot/test_path/")}, opts...)...)
+		_, generated, err := bot.Generate("./test_path/")
+		require.NoError(t, err)
+		schedules := make(map[config.Key]config.Schedule, len(generated.Updates))
+		for _, u := range generated.Updates {
+			schedules[u.Key()] = u.Schedule
+		}
+		return schedules
+	}
+
+	schedules := generate()
+	assert.Equal(t, schedules, generate(), "slots are deterministic")
+	days := map[string]bool{}
+	for key, schedule := range schedules {
+		assert.Contains(t, []string{"monday", "tuesday", "wednesday", "thursday"}, schedule.Day, key)
+		assert.Contains(t, []string{"06:00", "06:30", "07:00", "07:30", "08:00", "08:30", "09:00"}, schedule.Time, key)
+		days[schedule.Day] = true
+	}
+	assert.Greater(t, len(days), 1, "entries are spread over the days")
+
+	key := config.Key{Ecosystem: "gomod", Directory: "test_path/projectgo"}
+	existing := config.New()
+	existing.Updates = []config.Update{
+		{PackageEcosystem: "gomod", Directory: "test_path/projectgo", Schedule: config.Schedule{Interval: "weekly", Day: "thursday", Time: "06:15"}},
+		{PackageEcosystem: "pip", Directory: "test_path/projecte", Schedule: config.Schedule{Interval: "weekly", Day: "sunday", Time: "23:00"}},
+	}
+	pinned := generate(WithExisting(existing))
+	assert.Equal(t, config.Schedule{Interval: "weekly", Day: "thursday", Time: "06:15"}, pinned[key], "kept inside the window")
+	pipKey := config.Key{Ecosystem: "pip", Directory: "test_path/projecte"}
+	assert.Equal(t, schedules[pipKey], pinned[pipKey], "moved into the window")
+
+	assert.Equal(t, schedules, generate(WithExisting(existing), WithReshuffle(true)))
+}
+
+func TestStaggerTimes(t *testing.T) {
+	assert.Equal(t, []string{"08:00", "08:45", "09:30"}, staggerTimes(&settings.Stagger{From: "08:00", To: "10:00", Step: 45}))
+	assert.Equal(t, []string{"23:30"}, staggerTimes(&settings.Stagger{From: "23:30", To: "23:59"}))
+}
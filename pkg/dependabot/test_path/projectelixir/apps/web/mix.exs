@@ -0,0 +1,2 @@
+defmodule Web.MixProject do
+end
@@ -0,0 +1,2 @@
+defmodule Plug.MixProject do
+end
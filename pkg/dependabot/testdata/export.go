@@ -27,6 +27,10 @@ func PythonConfig() string {
 	return Content("dependabot-python.yaml")
 }
 
+func CargoConfig() string {
+	return Content("dependabot-cargo.yaml")
+}
+
 func HeaderNodeJSConfig() string {
 	return Content("dependabot-header-npm.yaml")
 }
@@ -42,3 +46,11 @@ func Content(name string) string {
 	}
 	return string(b)
 }
+
+func YAMLTerraformConfig() string {
+	return Content("dependabot-yaml-terraform.yaml")
+}
+
+func YAMLPythonConfig() string {
+	return Content("dependabot-yaml-python.yaml")
+}
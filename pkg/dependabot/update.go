@@ -0,0 +1,110 @@
+package dependabot
+
+import (
+	"cmp"
+	"slices"
+
+	"github.com/containifyci/dependabot-templater/pkg/config"
+	"github.com/containifyci/dependabot-templater/pkg/template"
+)
+
+// buildConfig converts the found kinds into the typed config model.
+func (d *DependaBot) buildConfig(found []kindResult) ([]string, *config.Config) {
+	cfg := config.New()
+	packages := make([]string, 0, len(found))
+	var referenced []string
+	for _, kr := range found {
+		packages = append(packages, kr.kind)
+		for _, entry := range kr.result.Entries {
+			cfg.Updates = append(cfg.Updates, newUpdate(kr.detector.Ecosystem(), entry))
+			referenced = append(referenced, entry.Registries...)
+		}
+	}
+	for _, name := range referenced {
+		if cfg.Registries == nil {
+			cfg.Registries = make(map[string]config.Registry)
+		}
+		cfg.Registries[name] = d.settings.Registries[name].Registry
+	}
+	return packages, cfg
+}
+
+// newUpdate builds the update entry with the same defaults as the embedded templates.
+func newUpdate(ecosystem string, entry template.DependaBotEntry) config.Update {
+	u := config.Update{
+		PackageEcosystem: ecosystem,
+		Directory:        entry.Directory,
+		Schedule:         config.Schedule{Interval: entry.Interval, Day: entry.Day, Time: entry.Time, Timezone: entry.Timezone, Cronjob: entry.Cronjob},
+		RebaseStrategy:   entry.RebaseStrategy,
+		CommitMessage: &config.CommitMessage{
+			Prefix:            entry.CommitMessagePrefix,
+			PrefixDevelopment: entry.CommitMessagePrefixDevelopment,
+			Include:           cmp.Or(entry.CommitMessageInclude, "scope"),
+		},
+		Registries:   slices.Clone(entry.Registries),
+		Labels:       slices.Clone(entry.Labels),
+		Assignees:    slices.Clone(entry.Assignees),
+		Reviewers:    slices.Clone(entry.Reviewers),
+		Milestone:    entry.Milestone,
+		TargetBranch: entry.TargetBranch,
+	}
+	if entry.OpenPullRequestsLimit != nil {
+		limit := *entry.OpenPullRequestsLimit
+		u.OpenPullRequestsLimit = &limit
+	}
+	if c := entry.Cooldown; c != nil {
+		u.Cooldown = &config.Cooldown{
+			DefaultDays:     days(c.DefaultDays),
+			SemverMajorDays: days(c.SemverMajorDays),
+			SemverMinorDays: days(c.SemverMinorDays),
+			SemverPatchDays: days(c.SemverPatchDays),
+			Include:         slices.Clone(c.Include),
+			Exclude:         slices.Clone(c.Exclude),
+		}
+	}
+	if entry.BranchNameSeparator != "" {
+		u.PullRequestBranchName = &config.BranchName{Separator: entry.BranchNameSeparator}
+	}
+	if ecosystem == "terraform" {
+		u.RebaseStrategy = cmp.Or(u.RebaseStrategy, "disabled")
+		if u.OpenPullRequestsLimit == nil {
+			limit := 1
+			u.OpenPullRequestsLimit = &limit
+		}
+	}
+	for _, g := range entry.Groups {
+		if u.Groups == nil {
+			u.Groups = make(map[string]config.Group, len(entry.Groups))
+		}
+		u.Groups[g.Name] = config.Group{
+			AppliesTo:       g.AppliesTo,
+			DependencyType:  g.DependencyType,
+			Patterns:        slices.Clone(g.Patterns),
+			ExcludePatterns: slices.Clone(g.ExcludePatterns),
+			UpdateTypes:     slices.Clone(g.UpdateTypes),
+		}
+	}
+	for _, i := range entry.Ignore {
+		u.Ignore = append(u.Ignore, config.Ignore{
+			DependencyName: i.DependencyName,
+			Versions:       slices.Clone(i.Versions),
+			UpdateTypes:    slices.Clone(i.UpdateTypes),
+		})
+	}
+	for _, a := range entry.Allow {
+		u.Allow = append(u.Allow, config.Allow{DependencyName: a.DependencyName, DependencyType: a.DependencyType})
+	}
+	if ecosystem == "pip" && len(u.Registries) > 0 {
+		// needed to access private registries, see https://docs.github.com/en/code-security/dependabot/working-with-dependabot/dependabot-options-reference#insecure-external-code-execution--
+		u.InsecureExternalCodeExecution = "allow"
+	}
+	return u
+}
+
+// days returns the cooldown days of the config, the templates leave out 0.
+func days(n int) *int {
+	if n == 0 {
+		return nil
+	}
+	return &n
+}
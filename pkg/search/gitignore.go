@@ -0,0 +1,175 @@
+package search
+
+import (
+	"bufio"
+	"bytes"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// ignorePattern is a single line of a gitignore file.
+type ignorePattern struct {
+	base     string // slash separated folder of the ignore file relative to the repository root
+	segments []string
+	negate   bool
+	dirOnly  bool
+}
+
+func parseIgnorePattern(base, line string) (ignorePattern, bool) {
+	line = strings.TrimRight(line, "\r")
+	if line == "" || strings.HasPrefix(line, "#") {
+		return ignorePattern{}, false
+	}
+	if !strings.HasSuffix(line, `\ `) {
+		line = strings.TrimRight(line, " ")
+	}
+	p := ignorePattern{base: base}
+	if strings.HasPrefix(line, "!") {
+		p.negate = true
+		line = line[1:]
+	} else if strings.HasPrefix(line, `\`) {
+		line = line[1:]
+	}
+	if strings.HasSuffix(line, "/") {
+		p.dirOnly = true
+		line = strings.TrimRight(line, "/")
+	}
+	if line == "" {
+		return ignorePattern{}, false
+	}
+	// Patterns without a slash except at the end match at any depth.
+	if !strings.Contains(line, "/") {
+		line = "**/" + line
+	}
+	p.segments = strings.Split(strings.TrimPrefix(line, "/"), "/")
+	return p, true
+}
+
+func (p ignorePattern) match(rel string, isDir bool) bool {
+	if p.dirOnly && !isDir {
+		return false
+	}
+	if p.base != "" {
+		if !strings.HasPrefix(rel, p.base+"/") {
+			return false
+		}
+		rel = strings.TrimPrefix(rel, p.base+"/")
+	}
+	return matchSegments(p.segments, strings.Split(rel, "/"))
+}
+
+// ignoreList evaluates gitignore patterns, the last matching pattern wins.
+type ignoreList struct {
+	patterns []ignorePattern
+}
+
+func (l *ignoreList) add(base string, data []byte) {
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		if p, ok := parseIgnorePattern(base, scanner.Text()); ok {
+			l.patterns = append(l.patterns, p)
+		}
+	}
+}
+
+func (l *ignoreList) addFile(base, file string) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return
+	}
+	l.add(base, data)
+}
+
+// ignored reports whether the slash separated path relative to the
+// repository root is ignored.
+func (l *ignoreList) ignored(rel string, isDir bool) bool {
+	ignored := false
+	for _, p := range l.patterns {
+		if p.match(rel, isDir) {
+			ignored = !p.negate
+		}
+	}
+	return ignored
+}
+
+// newIgnoreList loads the global excludes file, .git/info/exclude and the
+// .gitignore files of all folders between the repository root and dir.
+// The .gitignore files below dir are added while walking.
+func newIgnoreList(repo *repository, dir string) *ignoreList {
+	l := &ignoreList{}
+	if file := globalExcludesFile(); file != "" {
+		l.addFile("", file)
+	}
+	if repo.gitDir != "" {
+		l.addFile("", filepath.Join(repo.gitDir, "info", "exclude"))
+	}
+	rel := repo.rel(dir)
+	if rel == "." {
+		return l
+	}
+	l.addFile("", filepath.Join(repo.root, ".gitignore"))
+	parts := strings.Split(rel, "/")
+	for i := range parts[:len(parts)-1] {
+		base := path.Join(parts[:i+1]...)
+		l.addFile(base, filepath.Join(repo.root, filepath.FromSlash(base), ".gitignore"))
+	}
+	return l
+}
+
+// globalExcludesFile returns core.excludesFile from the user's git config or
+// the default location of the global ignore file.
+func globalExcludesFile() string {
+	home, _ := os.UserHomeDir()
+	configHome := os.Getenv("XDG_CONFIG_HOME")
+	if configHome == "" && home != "" {
+		configHome = filepath.Join(home, ".config")
+	}
+
+	var candidates []string
+	if configHome != "" {
+		candidates = append(candidates, filepath.Join(configHome, "git", "config"))
+	}
+	if home != "" {
+		candidates = append(candidates, filepath.Join(home, ".gitconfig"))
+	}
+	// ~/.gitconfig is read last by git and therefore wins.
+	file := ""
+	for _, candidate := range candidates {
+		if value := gitConfigValue(candidate, "core", "excludesfile"); value != "" {
+			file = value
+		}
+	}
+	if strings.HasPrefix(file, "~/") && home != "" {
+		file = filepath.Join(home, file[2:])
+	}
+	if file == "" && configHome != "" {
+		file = filepath.Join(configHome, "git", "ignore")
+	}
+	return file
+}
+
+func gitConfigValue(file, section, key string) string {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return ""
+	}
+	value := ""
+	current := ""
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";"):
+		case strings.HasPrefix(line, "["):
+			current = strings.ToLower(strings.Trim(line, "[]"))
+		case current == section:
+			k, v, ok := strings.Cut(line, "=")
+			if ok && strings.EqualFold(strings.TrimSpace(k), key) {
+				value = strings.Trim(strings.TrimSpace(v), `"`)
+			}
+		}
+	}
+	return value
+}
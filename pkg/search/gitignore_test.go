@@ -0,0 +1,98 @@
+package search
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIgnoreList(t *testing.T) {
+	t.Parallel()
+
+	l := &ignoreList{}
+	l.add("", []byte(`# comment
+*.log
+!keep.log
+/build
+.venv/
+docs/**/generated
+\#hash
+`))
+	l.add("services/api", []byte("tmp\n"))
+
+	for _, test := range []struct {
+		path    string
+		isDir   bool
+		ignored bool
+	}{
+		{"app.log", false, true},
+		{"deep/nested/app.log", false, true},
+		{"keep.log", false, false},
+		{"build", true, true},
+		{"src/build", true, false},
+		{".venv", true, true},
+		{"python/.venv", true, true},
+		{".venv", false, false},
+		{"docs/generated", true, true},
+		{"docs/a/b/generated", false, true},
+		{"#hash", false, true},
+		{"services/api/tmp", true, true},
+		{"services/web/tmp", true, false},
+		{"main.go", false, false},
+	} {
+		assert.Equal(t, test.ignored, l.ignored(test.path, test.isDir), test.path)
+	}
+}
+
+func TestWalkGitignore(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("HOME", dir)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(dir, ".config"))
+
+	repo := filepath.Join(dir, "repo")
+	for file, content := range map[string]string{
+		".config/git/ignore":                           "global-ignored/\n",
+		"repo/.git/info/exclude":                       "local/\n",
+		"repo/.gitignore":                              "dist/\n",
+		"repo/requirements.txt":                        "",
+		"repo/dist/requirements.txt":                   "",
+		"repo/local/requirements.txt":                  "",
+		"repo/global-ignored/requirements.txt":         "",
+		"repo/app/.gitignore":                          ".venv/\n",
+		"repo/app/requirements.txt":                    "",
+		"repo/app/.venv/lib/pkg/requirements.txt":      "",
+		"repo/other/.venv/lib/pkg/requirements.txt":    "",
+		"repo/other/requirements.txt":                  "",
+		"repo/other/generated/requirements.txt":        "",
+		"repo/other/generated/.gitignore":              "*\n!.gitignore\n",
+		"repo/other/generated/nested/requirements.txt": "",
+	} {
+		writeFile(t, filepath.Join(dir, file), content)
+	}
+
+	results, err := Walk(repo, map[string]Matcher{"pip": Files("requirements.txt")}, WithMode(ModeGitignore))
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		filepath.Join(repo, "app/requirements.txt"),
+		filepath.Join(repo, "other/.venv/lib/pkg/requirements.txt"),
+		filepath.Join(repo, "other/requirements.txt"),
+		filepath.Join(repo, "requirements.txt"),
+	}, results["pip"].Files)
+
+	// .gitignore files above the walked folder still apply
+	results, err = Walk(filepath.Join(repo, "app"), map[string]Matcher{"pip": Files("requirements.txt")}, WithMode(ModeGitignore))
+	require.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join(repo, "app/requirements.txt")}, results["pip"].Files)
+}
+
+func TestGlobalExcludesFile(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("HOME", dir)
+	t.Setenv("XDG_CONFIG_HOME", "")
+	assert.Equal(t, filepath.Join(dir, ".config/git/ignore"), globalExcludesFile())
+
+	writeFile(t, filepath.Join(dir, ".gitconfig"), "[user]\n\tname = test\n[core]\n\texcludesFile = ~/.gitignore_global\n")
+	assert.Equal(t, filepath.Join(dir, ".gitignore_global"), globalExcludesFile())
+}
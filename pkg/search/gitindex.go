@@ -0,0 +1,198 @@
+package search
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// repository is the git working tree a walk happens in.
+type repository struct {
+	root   string // absolute path of the working tree
+	gitDir string // absolute path of the git directory, empty outside of a repository
+}
+
+// findRepository looks for the .git entry in dir and its parents. Outside of
+// a repository dir itself is used as root.
+func findRepository(dir string) (*repository, error) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	for current := abs; ; current = filepath.Dir(current) {
+		gitDir, err := resolveGitDir(filepath.Join(current, ".git"))
+		if err == nil {
+			return &repository{root: current, gitDir: gitDir}, nil
+		}
+		if !errors.Is(err, fs.ErrNotExist) {
+			return nil, err
+		}
+		if filepath.Dir(current) == current {
+			return &repository{root: abs}, nil
+		}
+	}
+}
+
+// resolveGitDir follows the "gitdir:" file used by worktrees and submodules.
+func resolveGitDir(dotGit string) (string, error) {
+	info, err := os.Stat(dotGit)
+	if err != nil {
+		return "", err
+	}
+	if info.IsDir() {
+		return dotGit, nil
+	}
+	data, err := os.ReadFile(dotGit)
+	if err != nil {
+		return "", err
+	}
+	gitDir, ok := strings.CutPrefix(strings.TrimSpace(string(data)), "gitdir:")
+	if !ok {
+		return "", fmt.Errorf("invalid .git file %s", dotGit)
+	}
+	gitDir = strings.TrimSpace(gitDir)
+	if !filepath.IsAbs(gitDir) {
+		gitDir = filepath.Join(filepath.Dir(dotGit), gitDir)
+	}
+	return gitDir, nil
+}
+
+// rel returns the slash separated path relative to the repository root.
+func (r *repository) rel(path string) string {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return filepath.ToSlash(path)
+	}
+	rel, err := filepath.Rel(r.root, abs)
+	if err != nil {
+		return filepath.ToSlash(path)
+	}
+	return filepath.ToSlash(rel)
+}
+
+const gitlinkMode = 0o160000
+
+// readGitIndex returns the slash separated paths of all files tracked in the
+// index. It supports the index versions 2, 3 and 4 without needing a git binary.
+func readGitIndex(file string) ([]string, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < 12 || string(data[:4]) != "DIRC" {
+		return nil, fmt.Errorf("%s is not a git index", file)
+	}
+	version := binary.BigEndian.Uint32(data[4:8])
+	if version < 2 || version > 4 {
+		return nil, fmt.Errorf("unsupported git index version %d", version)
+	}
+	count := binary.BigEndian.Uint32(data[8:12])
+
+	const statSize = 40
+	hashSize := 20
+	if objectFormat(filepath.Dir(file)) == "sha256" {
+		hashSize = 32
+	}
+
+	var paths []string
+	var previous string
+	pos := 12
+	for range count {
+		start := pos
+		if pos+statSize+hashSize+2 > len(data) {
+			return nil, fmt.Errorf("truncated git index %s", file)
+		}
+		mode := binary.BigEndian.Uint32(data[pos+24 : pos+28])
+		pos += statSize + hashSize
+		flags := binary.BigEndian.Uint16(data[pos : pos+2])
+		pos += 2
+		if version >= 3 && flags&0x4000 != 0 {
+			pos += 2
+		}
+		stage := (flags >> 12) & 0x3
+
+		var name string
+		if version == 4 {
+			strip, n := binary.Uvarint(data[pos:])
+			if n <= 0 || int(strip) > len(previous) {
+				return nil, fmt.Errorf("corrupt git index %s", file)
+			}
+			pos += n
+			end := bytes.IndexByte(data[pos:], 0)
+			if end < 0 {
+				return nil, fmt.Errorf("truncated git index %s", file)
+			}
+			name = previous[:len(previous)-int(strip)] + string(data[pos:pos+end])
+			pos += end + 1
+		} else {
+			end := bytes.IndexByte(data[pos:], 0)
+			if end < 0 {
+				return nil, fmt.Errorf("truncated git index %s", file)
+			}
+			name = string(data[pos : pos+end])
+			pos += end
+			// Entries are padded with 1-8 NUL bytes to a multiple of eight.
+			pos += 8 - (pos-start)%8
+		}
+		previous = name
+
+		if stage > 1 || mode&0o170000 == gitlinkMode {
+			continue
+		}
+		paths = append(paths, name)
+	}
+	return slices.Compact(paths), nil
+}
+
+func objectFormat(gitDir string) string {
+	return strings.ToLower(gitConfigValue(filepath.Join(gitDir, "config"), "extensions", "objectformat"))
+}
+
+// indexEntry is the fs.DirEntry of a file listed in the git index.
+type indexEntry struct {
+	path string
+}
+
+func (e indexEntry) Name() string               { return filepath.Base(e.path) }
+func (e indexEntry) IsDir() bool                { return false }
+func (e indexEntry) Type() fs.FileMode          { return 0 }
+func (e indexEntry) Info() (fs.FileInfo, error) { return os.Lstat(e.path) }
+
+// walkIndex calls fn for every tracked file below dir in the order filepath.WalkDir would.
+func walkIndex(repo *repository, dir string, fn func(path string, d fs.DirEntry)) error {
+	if repo.gitDir == "" {
+		return fmt.Errorf("%s is not inside a git repository", dir)
+	}
+	files, err := readGitIndex(filepath.Join(repo.gitDir, "index"))
+	if err != nil {
+		return err
+	}
+	prefix := repo.rel(dir)
+	slices.SortFunc(files, compareWalkOrder)
+	for _, file := range files {
+		rel := file
+		if prefix != "." {
+			var ok bool
+			if rel, ok = strings.CutPrefix(file, prefix+"/"); !ok {
+				continue
+			}
+		}
+		if slices.ContainsFunc(strings.Split(filepath.Dir(rel), "/"), skipDir) {
+			continue
+		}
+		path := filepath.Join(dir, filepath.FromSlash(rel))
+		fn(path, indexEntry{path: path})
+	}
+	return nil
+}
+
+// compareWalkOrder sorts paths folder by folder like filepath.WalkDir visits them.
+func compareWalkOrder(a, b string) int {
+	return slices.Compare(strings.Split(a, "/"), strings.Split(b, "/"))
+}
@@ -0,0 +1,89 @@
+package search
+
+import (
+	"encoding/binary"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadGitIndex(t *testing.T) {
+	t.Parallel()
+
+	files := []string{"app/go.mod", "app/main.go", "sub", "zz/Dockerfile"}
+	modes := []uint32{0o100644, 0o100644, gitlinkMode, 0o100644}
+	for _, version := range []uint32{2, 3, 4} {
+		dir := t.TempDir()
+		index := filepath.Join(dir, "index")
+		writeFile(t, index, string(buildIndex(version, files, modes)))
+
+		paths, err := readGitIndex(index)
+		require.NoError(t, err, version)
+		assert.Equal(t, []string{"app/go.mod", "app/main.go", "zz/Dockerfile"}, paths, version)
+	}
+}
+
+func TestReadGitIndexInvalid(t *testing.T) {
+	t.Parallel()
+
+	index := filepath.Join(t.TempDir(), "index")
+	writeFile(t, index, "not an index")
+	_, err := readGitIndex(index)
+	assert.Error(t, err)
+}
+
+func TestWalkGitIndex(t *testing.T) {
+	t.Parallel()
+
+	repo := t.TempDir()
+	files := []string{"a.txt", "a/go.mod", "node_modules/x/package.json", "svc/go.mod", "svc/package.json"}
+	modes := []uint32{0o100644, 0o100644, 0o100644, 0o100644, 0o100644}
+	writeFile(t, filepath.Join(repo, ".git/index"), string(buildIndex(2, files, modes)))
+	writeFile(t, filepath.Join(repo, "svc/go.mod"), "")
+	writeFile(t, filepath.Join(repo, "untracked/go.mod"), "")
+
+	matchers := map[string]Matcher{"go": Files("go.mod"), "npm": Files("package.json")}
+	results, err := Walk(repo, matchers, WithMode(ModeGitIndex))
+	require.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join(repo, "a/go.mod"), filepath.Join(repo, "svc/go.mod")}, results["go"].Files)
+	assert.Equal(t, []string{filepath.Join(repo, "svc/package.json")}, results["npm"].Files)
+
+	results, err = Walk(filepath.Join(repo, "svc"), matchers, WithMode(ModeGitIndex))
+	require.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join(repo, "svc")}, results["go"].Folders)
+
+	_, err = Walk(t.TempDir(), matchers, WithMode(ModeGitIndex))
+	assert.Error(t, err)
+}
+
+// buildIndex writes a minimal git index with the given sorted paths.
+func buildIndex(version uint32, paths []string, modes []uint32) []byte {
+	data := []byte("DIRC")
+	data = binary.BigEndian.AppendUint32(data, version)
+	data = binary.BigEndian.AppendUint32(data, uint32(len(paths)))
+	previous := ""
+	for i, path := range paths {
+		start := len(data)
+		stat := make([]byte, 40)
+		binary.BigEndian.PutUint32(stat[24:28], modes[i])
+		data = append(data, stat...)
+		data = append(data, make([]byte, 20)...)
+		data = binary.BigEndian.AppendUint16(data, uint16(len(path)))
+		if version == 4 {
+			common := 0
+			for common < len(previous) && common < len(path) && previous[common] == path[common] {
+				common++
+			}
+			data = binary.AppendUvarint(data, uint64(len(previous)-common))
+			data = append(data, path[common:]...)
+			data = append(data, 0)
+		} else {
+			data = append(data, path...)
+			data = append(data, make([]byte, 8-(len(data)-start)%8)...)
+		}
+		previous = path
+	}
+	return data
+}
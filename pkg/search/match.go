@@ -0,0 +1,284 @@
+package search
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// Matcher selects files. The path is relative to the walk root so that the
+// folders above it don't take part, use FilePath to read the file.
+type Matcher interface {
+	Match(path string, d fs.DirEntry) bool
+}
+
+type MatcherFunc func(path string, d fs.DirEntry) bool
+
+func (f MatcherFunc) Match(path string, d fs.DirEntry) bool {
+	return f(path, d)
+}
+
+// rule is a Matcher that describes itself, which helps to explain why a file matched.
+type rule struct {
+	desc  string
+	match MatcherFunc
+	// explain replaces the description of combined rules by the ones of the
+	// rules that matched.
+	explain func(path string, d fs.DirEntry) (string, bool)
+}
+
+func (r rule) Match(path string, d fs.DirEntry) bool {
+	return r.match(path, d)
+}
+
+func (r rule) String() string {
+	return r.desc
+}
+
+func (r rule) Explain(path string, d fs.DirEntry) (string, bool) {
+	if r.explain != nil {
+		return r.explain(path, d)
+	}
+	if !r.match(path, d) {
+		return "", false
+	}
+	return r.desc, true
+}
+
+// Explain reports whether m matches the file and describes the rule that
+// matched. For Or only the first matching alternative is described.
+func Explain(m Matcher, path string, d fs.DirEntry) (string, bool) {
+	if e, ok := m.(interface {
+		Explain(path string, d fs.DirEntry) (string, bool)
+	}); ok {
+		return e.Explain(path, d)
+	}
+	if !m.Match(path, d) {
+		return "", false
+	}
+	return Describe(m), true
+}
+
+// Describe returns the description of matchers built by this package.
+func Describe(m Matcher) string {
+	if s, ok := m.(fmt.Stringer); ok {
+		return s.String()
+	}
+	return "custom matcher"
+}
+
+// Files matches files by their base name, ignoring case.
+func Files(targets ...string) Matcher {
+	return rule{desc: fmt.Sprintf("file name is one of %v", targets), match: func(path string, d fs.DirEntry) bool {
+		for _, target := range targets {
+			if strings.EqualFold(d.Name(), target) {
+				return true
+			}
+		}
+		return false
+	}}
+}
+
+// Suffix matches files whose base name ends with one of the suffixes, ignoring case.
+func Suffix(suffixes ...string) Matcher {
+	return rule{desc: fmt.Sprintf("file name ends with one of %v", suffixes), match: func(path string, d fs.DirEntry) bool {
+		for _, suffix := range suffixes {
+			if strings.HasSuffix(strings.ToLower(d.Name()), strings.ToLower(suffix)) {
+				return true
+			}
+		}
+		return false
+	}}
+}
+
+// Folder matches files whose parent folder ends with one of the targets, ignoring case.
+func Folder(targets ...string) Matcher {
+	return rule{desc: fmt.Sprintf("folder ends with one of %v", targets), match: func(path string, d fs.DirEntry) bool {
+		for _, target := range targets {
+			if strings.HasSuffix(strings.ToLower(filepath.Dir(path)), strings.ToLower(target)) {
+				return true
+			}
+		}
+		return false
+	}}
+}
+
+// Name matches the base name of files against globs like "Dockerfile.*", ignoring case.
+func Name(patterns ...string) Matcher {
+	return rule{desc: fmt.Sprintf("file name matches one of %v", patterns), match: func(path string, d fs.DirEntry) bool {
+		for _, pattern := range patterns {
+			if ok, _ := filepath.Match(strings.ToLower(pattern), strings.ToLower(d.Name())); ok {
+				return true
+			}
+		}
+		return false
+	}}
+}
+
+// Path matches the slash separated path of files against globs. A "**"
+// segment matches any number of folders. Patterns are matched against every
+// trailing part of the path relative to the walk root, so "modules/**"
+// matches files below any modules folder inside the root but not the root
+// itself being below a modules folder.
+func Path(patterns ...string) Matcher {
+	return rule{desc: fmt.Sprintf("path matches one of %v", patterns), match: func(p string, d fs.DirEntry) bool {
+		parts := strings.Split(filepath.ToSlash(filepath.Clean(p)), "/")
+		for _, pattern := range patterns {
+			segments := strings.Split(strings.Trim(pattern, "/"), "/")
+			for i := range parts {
+				if matchSegments(segments, parts[i:]) {
+					return true
+				}
+			}
+		}
+		return false
+	}}
+}
+
+// MatchGlob reports whether the slash separated path matches pattern from its
+// start. A "**" segment matches any number of folders.
+func MatchGlob(pattern, p string) bool {
+	segments := strings.Split(strings.Trim(path.Clean(pattern), "/"), "/")
+	return matchSegments(segments, strings.Split(path.Clean(filepath.ToSlash(p)), "/"))
+}
+
+// ValidateGlob returns path.ErrBadPattern for malformed patterns.
+func ValidateGlob(pattern string) error {
+	for _, segment := range strings.Split(pattern, "/") {
+		if _, err := path.Match(segment, ""); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func matchSegments(segments, parts []string) bool {
+	if len(segments) == 0 {
+		return len(parts) == 0
+	}
+	if segments[0] == "**" {
+		for i := 0; i <= len(parts); i++ {
+			if matchSegments(segments[1:], parts[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(parts) == 0 {
+		return false
+	}
+	if ok, _ := path.Match(segments[0], parts[0]); !ok {
+		return false
+	}
+	return matchSegments(segments[1:], parts[1:])
+}
+
+// Regexp matches the slash separated path of files.
+func Regexp(re *regexp.Regexp) Matcher {
+	return rule{desc: fmt.Sprintf("path matches /%s/", re), match: func(path string, d fs.DirEntry) bool {
+		return re.MatchString(filepath.ToSlash(path))
+	}}
+}
+
+// ContentFunc matches files with one of the extensions whose content satisfies
+// the predicate. Without extensions the content of every file is checked.
+func ContentFunc(desc string, predicate func(content []byte) bool, exts ...string) Matcher {
+	if len(exts) > 0 {
+		desc = fmt.Sprintf("%s in %v files", desc, exts)
+	}
+	return rule{desc: desc, match: func(path string, d fs.DirEntry) bool {
+		if len(exts) > 0 && !hasExt(d.Name(), exts) {
+			return false
+		}
+		content, err := os.ReadFile(FilePath(path, d))
+		if err != nil {
+			return false
+		}
+		return predicate(content)
+	}}
+}
+
+// Content matches files with the given extension that contain target.
+func Content(ext string, target string) Matcher {
+	return ContentFunc(fmt.Sprintf("content contains %q", target), func(content []byte) bool {
+		return bytes.Contains(content, []byte(target))
+	}, ext)
+}
+
+// ContentRegexp matches files with one of the extensions whose content matches re.
+func ContentRegexp(re *regexp.Regexp, exts ...string) Matcher {
+	return ContentFunc(fmt.Sprintf("content matches /%s/", re), re.Match, exts...)
+}
+
+func hasExt(name string, exts []string) bool {
+	for _, ext := range exts {
+		if strings.HasSuffix(strings.ToLower(name), strings.ToLower(ext)) {
+			return true
+		}
+	}
+	return false
+}
+
+// And matches files matched by all matchers.
+func And(matchers ...Matcher) Matcher {
+	return rule{desc: join("all of", matchers), match: func(path string, d fs.DirEntry) bool {
+		for _, m := range matchers {
+			if !m.Match(path, d) {
+				return false
+			}
+		}
+		return true
+	}, explain: func(path string, d fs.DirEntry) (string, bool) {
+		descs := make([]string, len(matchers))
+		for i, m := range matchers {
+			desc, ok := Explain(m, path, d)
+			if !ok {
+				return "", false
+			}
+			descs[i] = desc
+		}
+		if len(descs) == 1 {
+			return descs[0], true
+		}
+		return fmt.Sprintf("all of (%s)", strings.Join(descs, "; ")), true
+	}}
+}
+
+// Or matches files matched by any of the matchers.
+func Or(matchers ...Matcher) Matcher {
+	return rule{desc: join("any of", matchers), match: func(path string, d fs.DirEntry) bool {
+		for _, m := range matchers {
+			if m.Match(path, d) {
+				return true
+			}
+		}
+		return false
+	}, explain: func(path string, d fs.DirEntry) (string, bool) {
+		for _, m := range matchers {
+			if desc, ok := Explain(m, path, d); ok {
+				return desc, true
+			}
+		}
+		return "", false
+	}}
+}
+
+// Not matches files not matched by m.
+func Not(m Matcher) Matcher {
+	return rule{desc: fmt.Sprintf("not (%s)", Describe(m)), match: func(path string, d fs.DirEntry) bool {
+		return !m.Match(path, d)
+	}}
+}
+
+func join(op string, matchers []Matcher) string {
+	descs := make([]string, len(matchers))
+	for i, m := range matchers {
+		descs[i] = Describe(m)
+	}
+	return fmt.Sprintf("%s (%s)", op, strings.Join(descs, "; "))
+}
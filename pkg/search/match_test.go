@@ -0,0 +1,142 @@
+package search
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatchers(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	for file, content := range map[string]string{
+		"infra/prod/main.tf":              "terraform {\n  backend \"gcs\" {}\n}\n",
+		"infra/modules/network/main.tf":   "terraform {\n  required_version = \">= 1.0\"\n}\n",
+		"infra/prod/variables.tf":         "variable \"name\" {}\n",
+		"services/api/Dockerfile.release": "",
+		"services/api/Dockerfile":         "",
+		"services/api/api.csproj":         "",
+		"docs/README.md":                  "terraform {",
+	} {
+		writeFile(t, filepath.Join(dir, file), content)
+	}
+
+	terraformBlock := regexp.MustCompile(`(?m)^terraform\s*\{`)
+	for _, test := range []struct {
+		name    string
+		matcher Matcher
+		files   []string
+	}{
+		{
+			name:    "basename glob",
+			matcher: Name("dockerfile*"),
+			files:   []string{"services/api/Dockerfile", "services/api/Dockerfile.release"},
+		},
+		{
+			name:    "extension glob",
+			matcher: Name("*.csproj"),
+			files:   []string{"services/api/api.csproj"},
+		},
+		{
+			name:    "path glob",
+			matcher: Path("infra/**/*.tf"),
+			files:   []string{"infra/modules/network/main.tf", "infra/prod/main.tf", "infra/prod/variables.tf"},
+		},
+		{
+			name:    "regexp",
+			matcher: Regexp(regexp.MustCompile(`/api/[^/]+\.csproj$`)),
+			files:   []string{"services/api/api.csproj"},
+		},
+		{
+			name:    "content limited to extensions",
+			matcher: ContentRegexp(terraformBlock, ".tf"),
+			files:   []string{"infra/modules/network/main.tf", "infra/prod/main.tf"},
+		},
+		{
+			name:    "terraform block not in modules",
+			matcher: And(ContentRegexp(terraformBlock, ".tf"), Not(Path("modules/**"))),
+			files:   []string{"infra/prod/main.tf"},
+		},
+		{
+			name:    "any docker or dotnet file",
+			matcher: Or(Files("Dockerfile"), Suffix(".csproj")),
+			files:   []string{"services/api/Dockerfile", "services/api/api.csproj"},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			results, err := Walk(dir, map[string]Matcher{"": test.matcher})
+			require.NoError(t, err)
+
+			files := make([]string, len(results[""].Files))
+			for i, file := range results[""].Files {
+				files[i], err = filepath.Rel(dir, file)
+				require.NoError(t, err)
+				files[i] = filepath.ToSlash(files[i])
+			}
+			assert.Equal(t, test.files, files)
+		})
+	}
+}
+
+func TestMatchSegments(t *testing.T) {
+	t.Parallel()
+
+	assert.True(t, matchSegments([]string{"**"}, []string{"a", "b"}))
+	assert.True(t, matchSegments([]string{"a", "**", "c"}, []string{"a", "c"}))
+	assert.True(t, matchSegments([]string{"a", "**", "c"}, []string{"a", "b", "b", "c"}))
+	assert.False(t, matchSegments([]string{"a", "*"}, []string{"a"}))
+	assert.False(t, matchSegments([]string{"a", "**", "c"}, []string{"a", "b"}))
+}
+
+func TestMatcherString(t *testing.T) {
+	t.Parallel()
+
+	m := And(Content(".tf", "backend"), Not(Path("modules/**")))
+	assert.Equal(t, `all of (content contains "backend" in [.tf] files; not (path matches one of [modules/**]))`, Describe(m))
+	assert.Equal(t, "custom matcher", Describe(MatcherFunc(nil)))
+}
+
+func TestExplain(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "app", "Cargo.toml"), "")
+	writeFile(t, filepath.Join(dir, "target", "Cargo.toml"), "")
+	m := And(Or(Files("Gemfile"), Files("Cargo.toml")), Not(Path("target/**/*")))
+
+	entry := func(path string) fs.DirEntry {
+		info, err := os.Lstat(path)
+		require.NoError(t, err)
+		return fs.FileInfoToDirEntry(info)
+	}
+	file := filepath.Join(dir, "app", "Cargo.toml")
+	desc, ok := Explain(m, file, entry(file))
+	assert.True(t, ok)
+	assert.Equal(t, "all of (file name is one of [Cargo.toml]; not (path matches one of [target/**/*]))", desc)
+
+	file = filepath.Join(dir, "target", "Cargo.toml")
+	_, ok = Explain(m, file, entry(file))
+	assert.False(t, ok)
+
+	desc, ok = Explain(MatcherFunc(func(string, fs.DirEntry) bool { return true }), file, entry(file))
+	assert.True(t, ok)
+	assert.Equal(t, "custom matcher", desc)
+}
+
+func TestMatchGlob(t *testing.T) {
+	t.Parallel()
+
+	assert.True(t, MatchGlob("services/**", "services/api"))
+	assert.True(t, MatchGlob("services/**", "services"))
+	assert.True(t, MatchGlob("**/web", "apps/frontend/web"))
+	assert.True(t, MatchGlob("./services/*", "services/api/"))
+	assert.False(t, MatchGlob("services/*", "legacy/services/api"))
+	assert.NoError(t, ValidateGlob("services/**/*.go"))
+	assert.Error(t, ValidateGlob("services/[a"))
+}
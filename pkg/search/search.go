@@ -1,8 +1,6 @@
 package search
 
 import (
-	"log"
-	"os"
 	"path/filepath"
 	"strings"
 )
@@ -33,108 +31,25 @@ func NormalizePath(path string) string {
 }
 
 func SearchForString(dir string, target string) ([]string, error) {
-	foundFiles := UniqueStringSlice{
-		unqiue: make(map[string]bool),
-	}
-
-	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return err
-		}
-		if info.IsDir() {
-			return nil
-		}
-		if strings.Contains(path, ".terraform") {
-			return nil
-		}
-		if !strings.HasSuffix(info.Name(), ".tf") {
-			return nil
-		}
-		if contains(path, target) {
-			foundFiles.Add(path)
-		}
-		return nil
-	})
-	if err != nil {
-		return nil, err
-	}
-
-	return foundFiles.elements, nil
+	return search(dir, Content(".tf", target))
 }
 
 func SearchForFolder(dir string, targets ...string) ([]string, error) {
-	foundFiles := UniqueStringSlice{
-		unqiue: make(map[string]bool),
-	}
-
-	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return err
-		}
-		if info.IsDir() {
-			return nil
-		}
-		for _, target := range targets {
-			if strings.HasSuffix(strings.ToLower(filepath.Dir(path)), strings.ToLower(target)) {
-				foundFiles.Add(path)
-			}
-		}
-		return nil
-	})
-	if err != nil {
-		return nil, err
-	}
-
-	return foundFiles.elements, nil
+	return search(dir, Folder(targets...))
 }
 
 func SearchForFiles(dir string, targets ...string) ([]string, error) {
-	foundFiles := UniqueStringSlice{
-		unqiue: make(map[string]bool),
-	}
-
-	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return err
-		}
-		if info.IsDir() {
-			return nil
-		}
-		for _, target := range targets {
-			if strings.EqualFold(filepath.Base(path), target) {
-				foundFiles.Add(path)
-			}
-		}
-		return nil
-	})
-	if err != nil {
-		return nil, err
-	}
+	return search(dir, Files(targets...))
+}
 
-	return foundFiles.elements, nil
+func SearchForSuffix(dir string, suffixes ...string) ([]string, error) {
+	return search(dir, Suffix(suffixes...))
 }
 
-func contains(file string, target string) bool {
-	f, err := os.Open(file)
+func search(dir string, matcher Matcher) ([]string, error) {
+	results, err := Walk(dir, map[string]Matcher{"": matcher})
 	if err != nil {
-		return false
-	}
-	defer func() {
-		err := f.Close()
-		if err != nil {
-			log.Printf("failed to close file: %s", err)
-		}
-	}()
-
-	buf := make([]byte, 1024)
-	for {
-		n, err := f.Read(buf)
-		if err != nil || n == 0 {
-			break
-		}
-		if strings.Contains(string(buf[:n]), target) {
-			return true
-		}
+		return nil, err
 	}
-	return false
+	return results[""].Folders, nil
 }
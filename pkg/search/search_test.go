@@ -1,9 +1,14 @@
 package search
 
 import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestUniqueSlice(t *testing.T) {
@@ -34,6 +39,14 @@ func TestSearchForFiles(t *testing.T) {
 	assert.Equal(t, []string{"test_path/projecte"}, foundFiles)
 }
 
+func TestSearchForSuffix(t *testing.T) {
+	t.Parallel()
+
+	foundFiles, err := SearchForSuffix("./test_path", ".TXT", ".toml")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"test_path/projecta", "test_path/projecte", "test_path/projectf"}, foundFiles)
+}
+
 func TestSearchForString(t *testing.T) {
 	t.Parallel()
 
@@ -66,6 +79,105 @@ func TestSearchNoResult(t *testing.T) {
 	assert.Equal(t, nilSlice, foundFiles)
 }
 
+func TestWalk(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	for _, file := range []string{
+		"app/package.json",
+		"app/node_modules/left-pad/package.json",
+		"app/vendor/lib/go.mod",
+		"infra/.terraform/modules/main.tf",
+		".git/package.json",
+		"go.mod",
+		"gems/Awesome.GemSpec",
+	} {
+		writeFile(t, filepath.Join(dir, file), "")
+	}
+
+	results, err := Walk(dir, map[string]Matcher{
+		"npm": Files("package.json"),
+		"go":  Files("go.mod"),
+		"tf":  Content(".tf", "backend"),
+		"gem": Suffix(".gemspec"),
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join(dir, "app/package.json")}, results["npm"].Files)
+	assert.Equal(t, []string{filepath.Join(dir, "go.mod")}, results["go"].Files)
+	assert.Empty(t, results["tf"].Files)
+	assert.Equal(t, []string{filepath.Join(dir, "gems")}, results["gem"].Folders)
+}
+
+func TestWalkRelativePaths(t *testing.T) {
+	t.Parallel()
+
+	// The checkout itself lives below a folder the rules exclude.
+	dir := filepath.Join(t.TempDir(), "target", "repo")
+	writeFile(t, filepath.Join(dir, "Cargo.toml"), "")
+	writeFile(t, filepath.Join(dir, "target/debug/Cargo.toml"), "")
+	writeFile(t, filepath.Join(dir, "infra/main.tf"), "backend")
+
+	results, err := Walk(dir, map[string]Matcher{
+		"cargo": And(Files("Cargo.toml"), Not(Path("target/**/*"))),
+		"tf":    And(Content(".tf", "backend"), Path("infra/*")),
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join(dir, "Cargo.toml")}, results["cargo"].Files)
+	assert.Equal(t, []string{filepath.Join(dir, "infra/main.tf")}, results["tf"].Files)
+}
+
+func TestWalkError(t *testing.T) {
+	t.Parallel()
+
+	_, err := Walk("./test_path/missing", map[string]Matcher{"go": Files("go.mod")})
+	assert.ErrorIs(t, err, fs.ErrNotExist)
+}
+
+func TestWalkSkipsUnreadable(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "app/go.mod"), "")
+	writeFile(t, filepath.Join(dir, "web/package.json"), "")
+	broken := unreadableDir(t, dir)
+
+	var skipped []string
+	results, err := Walk(dir, map[string]Matcher{
+		"go":  Files("go.mod"),
+		"npm": Files("package.json"),
+	}, WithSkipped(func(path string, err error) { skipped = append(skipped, path) }))
+	require.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join(dir, "app/go.mod")}, results["go"].Files)
+	assert.Equal(t, []string{filepath.Join(dir, "web/package.json")}, results["npm"].Files)
+	require.Len(t, skipped, 1)
+	assert.True(t, strings.HasPrefix(skipped[0], broken), skipped[0])
+}
+
 // test utility
 
+// unreadableDir creates a folder below dir that can't be walked, even as
+// root, as its nested path exceeds the maximum path length. It returns the
+// first folder of the nesting.
+func unreadableDir(t *testing.T, dir string) string {
+	t.Helper()
+	name := strings.Repeat("d", 250)
+	root, err := os.OpenRoot(dir)
+	require.NoError(t, err)
+	for range 20 {
+		require.NoError(t, root.Mkdir(name, 0o755))
+		next, err := root.OpenRoot(name)
+		require.NoError(t, err)
+		require.NoError(t, root.Close())
+		root = next
+	}
+	require.NoError(t, root.Close())
+	return filepath.Join(dir, name)
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	assert.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+	assert.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+}
+
 var nilSlice []string
@@ -0,0 +1,202 @@
+package search
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+)
+
+// SkipDirs lists directory names that are never descended into while walking.
+var SkipDirs = []string{".git", ".terraform", "node_modules", "vendor"}
+
+// Result holds the unique folders and the matching files found for a single matcher.
+type Result struct {
+	Folders []string
+	Files   []string
+}
+
+// Mode selects which files a walk visits.
+type Mode int
+
+const (
+	// ModeAll visits every file that is not below one of the SkipDirs.
+	ModeAll Mode = iota
+	// ModeGitignore additionally skips files ignored by .gitignore files,
+	// .git/info/exclude and the global excludes file.
+	ModeGitignore
+	// ModeGitIndex only visits files tracked in the git index.
+	ModeGitIndex
+)
+
+type walkOptions struct {
+	mode    Mode
+	skipped func(path string, err error)
+}
+
+type WalkOption func(*walkOptions)
+
+func WithMode(mode Mode) WalkOption {
+	return func(o *walkOptions) {
+		o.mode = mode
+	}
+}
+
+// WithSkipped reports the paths below the walk root that can't be read to
+// fn, the walk skips them and goes on.
+func WithSkipped(fn func(path string, err error)) WalkOption {
+	return func(o *walkOptions) {
+		o.skipped = fn
+	}
+}
+
+// skip handles the error of path, only an error of the root dir ends the walk.
+func (o walkOptions) skip(dir, path string, d fs.DirEntry, err error) error {
+	if path == dir {
+		return err
+	}
+	if o.skipped != nil {
+		o.skipped(path, err)
+	}
+	if d != nil && d.IsDir() {
+		return filepath.SkipDir
+	}
+	return nil
+}
+
+// Walk traverses dir once and hands every file to all matchers with its path
+// relative to dir, see Rel. The results hold the walked paths and are keyed by
+// the same names as the matchers. Unreadable paths below dir are skipped, see
+// WithSkipped, an error is only returned when dir itself can't be walked.
+func Walk(dir string, matchers map[string]Matcher, opts ...WalkOption) (map[string]*Result, error) {
+	options := walkOptions{}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	names := make([]string, 0, len(matchers))
+	for name := range matchers {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	folders := make(map[string]*UniqueStringSlice, len(matchers))
+	results := make(map[string]*Result, len(matchers))
+	for _, name := range names {
+		folders[name] = &UniqueStringSlice{unqiue: make(map[string]bool)}
+		results[name] = &Result{}
+	}
+
+	visit := func(path string, d fs.DirEntry) {
+		rel, entry := Rel(dir, path, d)
+		for _, name := range names {
+			if matchers[name].Match(rel, entry) {
+				folders[name].Add(path)
+				results[name].Files = append(results[name].Files, path)
+			}
+		}
+	}
+
+	var err error
+	switch options.mode {
+	case ModeGitIndex:
+		err = walkGitIndex(dir, visit)
+	case ModeGitignore:
+		err = walkGitignore(dir, options, visit)
+	default:
+		err = walkAll(dir, options, visit)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	for _, name := range names {
+		results[name].Folders = folders[name].elements
+	}
+	return results, nil
+}
+
+// fileEntry carries the walked path of a file to the matchers.
+type fileEntry struct {
+	fs.DirEntry
+	path string
+}
+
+// Rel returns the path of file relative to the walk root root and d
+// annotated with file, like they are handed to the matchers by Walk.
+func Rel(root, file string, d fs.DirEntry) (string, fs.DirEntry) {
+	rel, err := filepath.Rel(root, file)
+	if err != nil {
+		rel = file
+	}
+	return rel, fileEntry{DirEntry: d, path: file}
+}
+
+// FilePath returns the path to read the file handed to a matcher, path is
+// relative to the walk root.
+func FilePath(path string, d fs.DirEntry) string {
+	if e, ok := d.(fileEntry); ok {
+		return e.path
+	}
+	return path
+}
+
+func walkAll(dir string, options walkOptions, visit func(path string, d fs.DirEntry)) error {
+	return filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return options.skip(dir, path, d, err)
+		}
+		if d.IsDir() {
+			if path != dir && skipDir(d.Name()) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		visit(path, d)
+		return nil
+	})
+}
+
+func walkGitignore(dir string, options walkOptions, visit func(path string, d fs.DirEntry)) error {
+	repo, err := findRepository(dir)
+	if err != nil {
+		return err
+	}
+	ignores := newIgnoreList(repo, dir)
+	return filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return options.skip(dir, path, d, err)
+		}
+		rel := repo.rel(path)
+		if d.IsDir() {
+			if path != dir && (skipDir(d.Name()) || ignores.ignored(rel, true)) {
+				return filepath.SkipDir
+			}
+			base := rel
+			if base == "." {
+				base = ""
+			}
+			ignores.addFile(base, filepath.Join(path, ".gitignore"))
+			return nil
+		}
+		if !ignores.ignored(rel, false) {
+			visit(path, d)
+		}
+		return nil
+	})
+}
+
+func walkGitIndex(dir string, visit func(path string, d fs.DirEntry)) error {
+	if _, err := os.Stat(dir); err != nil {
+		return err
+	}
+	repo, err := findRepository(dir)
+	if err != nil {
+		return err
+	}
+	return walkIndex(repo, dir, visit)
+}
+
+func skipDir(name string) bool {
+	return slices.Contains(SkipDirs, name)
+}
@@ -0,0 +1,54 @@
+package settings
+
+import (
+	"slices"
+	"sort"
+
+	"github.com/containifyci/dependabot-templater/pkg/config"
+	"github.com/containifyci/dependabot-templater/pkg/search"
+)
+
+// Registry is a private registry as defined by Dependabot. It is attached to
+// the entries of Kinds and Directories, the header only lists the registries
+// attached to at least one entry.
+type Registry struct {
+	config.Registry `yaml:",inline"`
+
+	// Kinds attaches the registry to the entries of the given kinds.
+	Kinds []string `yaml:"kinds,omitempty"`
+	// Directories attaches the registry to the entries of directories matching
+	// one of the globs. Combined with Kinds both have to match.
+	Directories []string `yaml:"directories,omitempty"`
+}
+
+// Supports reports whether the registry type can be used with ecosystem.
+func (r Registry) Supports(ecosystem string) bool {
+	ecosystems, ok := config.RegistryTypes[r.Type]
+	return ok && (ecosystems == nil || slices.Contains(ecosystems, ecosystem))
+}
+
+func (r Registry) attached(kind, dir string) bool {
+	if len(r.Kinds) == 0 && len(r.Directories) == 0 {
+		return false
+	}
+	if len(r.Kinds) > 0 && !slices.Contains(r.Kinds, kind) {
+		return false
+	}
+	if len(r.Directories) > 0 && !slices.ContainsFunc(r.Directories, func(glob string) bool { return search.MatchGlob(glob, dir) }) {
+		return false
+	}
+	return true
+}
+
+// RegistriesFor returns the sorted names of the registries attached to the
+// entry of kind in dir that support the ecosystem of the entry.
+func (s *Settings) RegistriesFor(kind, ecosystem, dir string) []string {
+	var names []string
+	for name, r := range s.Registries {
+		if r.attached(kind, dir) && r.Supports(ecosystem) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
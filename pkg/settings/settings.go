@@ -0,0 +1,460 @@
+package settings
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/containifyci/dependabot-templater/pkg/config"
+	"github.com/containifyci/dependabot-templater/pkg/search"
+
+	"gopkg.in/yaml.v3"
+)
+
+// FileNames are the names of the config file looked up in a repository.
+var FileNames = []string{".dependabot-templater.yaml", ".dependabot-templater.yml"}
+
+// Settings is the content of the .dependabot-templater.yaml config file.
+type Settings struct {
+	// Kinds selects the detectors to run, "all" selects every registered one.
+	Kinds []string `yaml:"kinds,omitempty"`
+	// Scan is the scan mode: all, gitignore or git-index.
+	Scan string `yaml:"scan,omitempty"`
+	// Include restricts the generated entries to directories matching one of the globs.
+	Include []string `yaml:"include,omitempty"`
+	// Exclude drops generated entries of directories matching one of the globs.
+	Exclude []string `yaml:"exclude,omitempty"`
+	// Backend renders the config with the typed model (yaml, default) or the
+	// embedded templates (template).
+	Backend string `yaml:"backend,omitempty"`
+	// Output is the path of the generated dependabot.yml relative to the config file.
+	Output string `yaml:"output,omitempty"`
+	// Merge updates the generated entries of an existing dependabot.yml instead
+	// of replacing the file, see config.Merge.
+	Merge bool `yaml:"merge,omitempty"`
+	// Stale selects what a merge does with entries that are no longer
+	// generated: remove (default) or mark.
+	Stale string `yaml:"stale,omitempty"`
+
+	Options `yaml:",inline"`
+
+	// CodeOwners adds the owners of the CODEOWNERS file to the entries.
+	CodeOwners *CodeOwners `yaml:"codeowners,omitempty"`
+
+	// Registries are the private registries by name.
+	Registries map[string]Registry `yaml:"registries,omitempty"`
+
+	// Ecosystems holds the options per kind.
+	Ecosystems map[string]Options `yaml:"ecosystems,omitempty"`
+	// Directories holds the options per directory glob, later entries win.
+	Directories []Directory `yaml:"directories,omitempty"`
+
+	// File is the path the settings were loaded from.
+	File string `yaml:"-"`
+
+	doc *document
+}
+
+// document is the parsed config file, it is kept to report the lines of
+// problems found after parsing, see CheckKinds.
+type document struct {
+	file string
+	root *yaml.Node
+}
+
+// Options can be set globally, per kind and per directory. Lists replace
+// the lists of a less specific level.
+type Options struct {
+	Schedule *Schedule `yaml:"schedule,omitempty"`
+
+	Labels    []string `yaml:"labels,omitempty"`
+	Assignees []string `yaml:"assignees,omitempty"`
+	Reviewers []string `yaml:"reviewers,omitempty"`
+	// Milestone is the number of the milestone of the pull requests.
+	Milestone    int    `yaml:"milestone,omitempty"`
+	TargetBranch string `yaml:"target-branch,omitempty"`
+	// OpenPullRequestsLimit is a pointer as 0 disables version updates.
+	OpenPullRequestsLimit *int                  `yaml:"open-pull-requests-limit,omitempty"`
+	RebaseStrategy        string                `yaml:"rebase-strategy,omitempty"`
+	PullRequestBranchName *config.BranchName    `yaml:"pull-request-branch-name,omitempty"`
+	CommitMessage         *config.CommitMessage `yaml:"commit-message,omitempty"`
+
+	// GroupPreset selects built-in groups, one of GroupPresets. By default
+	// terraform has no groups and the other ecosystems the minor preset.
+	GroupPreset string `yaml:"group-preset,omitempty"`
+	// Groups are added to the groups of the preset. They are merged by name,
+	// a group replaces the group of the same name of a less specific level.
+	// The preset groups exclude the patterns of the version update groups.
+	Groups map[string]config.Group `yaml:"groups,omitempty"`
+
+	// RulePresets select built-in ignore and allow rules, see RulePresets. An
+	// empty list drops the majors-only default of terraform.
+	RulePresets []string        `yaml:"rule-presets,omitempty"`
+	Ignore      []config.Ignore `yaml:"ignore,omitempty"`
+	Allow       []config.Allow  `yaml:"allow,omitempty"`
+
+	// Cooldown delays updates of new releases. It is merged key by key, the
+	// include and exclude lists replace the lists of a less specific level
+	// and 0 days turn off the days of a less specific level.
+	Cooldown *config.Cooldown `yaml:"cooldown,omitempty"`
+}
+
+type Schedule struct {
+	Interval string `yaml:"interval,omitempty"`
+	Day      string `yaml:"day,omitempty"`
+	// Time is the time of day formatted as hh:mm.
+	Time string `yaml:"time,omitempty"`
+	// Timezone is the IANA time zone of Time or Cronjob.
+	Timezone string `yaml:"timezone,omitempty"`
+	// Cronjob is a five field cron expression, it implies the cron interval.
+	Cronjob string `yaml:"cronjob,omitempty"`
+	// Stagger spreads the entries over days and times instead of Day and Time.
+	Stagger *Stagger `yaml:"stagger,omitempty"`
+}
+
+// Stagger spreads the entries over a window of days and times. Every entry
+// gets a fixed slot derived from a hash of its ecosystem and directory, so
+// adding entries doesn't move the others.
+type Stagger struct {
+	// Days are the weekdays weekly entries are spread over.
+	Days []string `yaml:"days,omitempty"`
+	// From and To bound the times of day entries are spread over, formatted
+	// as hh:mm. Both ends are included.
+	From string `yaml:"from,omitempty"`
+	To   string `yaml:"to,omitempty"`
+	// Step is the distance between two times in minutes, 30 by default.
+	Step int `yaml:"step,omitempty"`
+}
+
+// CodeOwners selects how the owners of a directory are added to its entries.
+// Reviewers and assignees set in the options take precedence.
+type CodeOwners struct {
+	// File is the CODEOWNERS file relative to the config file, by default it
+	// is looked up in the repository like GitHub does.
+	File string `yaml:"file,omitempty"`
+	// Reviewers adds the users and teams owning the directory as reviewers.
+	Reviewers bool `yaml:"reviewers,omitempty"`
+	// Assignees adds the users owning the directory as assignees, teams
+	// can't be assigned.
+	Assignees bool `yaml:"assignees,omitempty"`
+	// TeamLabels adds a team:<name> label for every owning team.
+	TeamLabels bool `yaml:"team-labels,omitempty"`
+}
+
+type Directory struct {
+	// Path is a glob like "services/**" matched against the generated directory.
+	Path string `yaml:"path"`
+	// Kinds limits the override to the given kinds.
+	Kinds []string `yaml:"kinds,omitempty"`
+
+	Options `yaml:",inline"`
+}
+
+// Find returns the first config file found in dirs.
+func Find(dirs ...string) (string, bool) {
+	for _, dir := range dirs {
+		for _, name := range FileNames {
+			file := filepath.Join(dir, name)
+			if info, err := os.Stat(file); err == nil && !info.IsDir() {
+				return file, true
+			}
+		}
+	}
+	return "", false
+}
+
+// Load reads the first config file found in dirs. Without a config file
+// empty settings are returned.
+func Load(dirs ...string) (*Settings, error) {
+	file, ok := Find(dirs...)
+	if !ok {
+		return &Settings{}, nil
+	}
+	return LoadFile(file)
+}
+
+func LoadFile(file string) (*Settings, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	s, err := Parse(file, data)
+	if err != nil {
+		return nil, err
+	}
+	s.File = file
+	return s, nil
+}
+
+// Parse decodes and validates the config. Problems are reported as Errors
+// with the line they occur on.
+func Parse(file string, data []byte) (*Settings, error) {
+	s := &Settings{}
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return nil, config.ParseError(file, err)
+	}
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(s); err != nil && !errors.Is(err, io.EOF) {
+		return nil, config.ParseError(file, err)
+	}
+	if errs := s.validate(file, &root); len(errs) > 0 {
+		return nil, errs
+	}
+	if root.Kind != 0 {
+		s.doc = &document{file: file, root: &root}
+	}
+	return s, nil
+}
+
+// ScanMode returns the search.Mode selected by Scan.
+func (s *Settings) ScanMode() search.Mode {
+	switch s.Scan {
+	case "gitignore":
+		return search.ModeGitignore
+	case "git-index":
+		return search.ModeGitIndex
+	default:
+		return search.ModeAll
+	}
+}
+
+// CodeOwnersPath returns the configured CODEOWNERS file relative to the
+// directory of the config file.
+func (s *Settings) CodeOwnersPath() string {
+	if s.CodeOwners == nil {
+		return ""
+	}
+	file := s.CodeOwners.File
+	if file == "" || filepath.IsAbs(file) || s.File == "" {
+		return file
+	}
+	return filepath.Join(filepath.Dir(s.File), file)
+}
+
+// OutputPath returns Output relative to the directory of the config file.
+func (s *Settings) OutputPath() string {
+	if s.Output == "" || filepath.IsAbs(s.Output) || s.File == "" {
+		return s.Output
+	}
+	return filepath.Join(filepath.Dir(s.File), s.Output)
+}
+
+// Included reports whether dir passes the include and exclude globs.
+func (s *Settings) Included(dir string) bool {
+	return s.Exclusion(dir) == ""
+}
+
+// Exclusion returns why dir is dropped by the include and exclude globs or an
+// empty string if it is included.
+func (s *Settings) Exclusion(dir string) string {
+	if len(s.Include) > 0 && !slices.ContainsFunc(s.Include, func(glob string) bool { return search.MatchGlob(glob, dir) }) {
+		return fmt.Sprintf("not matched by include %v", s.Include)
+	}
+	for _, glob := range s.Exclude {
+		if search.MatchGlob(glob, dir) {
+			return fmt.Sprintf("matched by exclude %q", glob)
+		}
+	}
+	return ""
+}
+
+// Resolve merges the global options with the ones of the kind and of all
+// directory globs matching dir, in that order.
+func (s *Settings) Resolve(kind, dir string) Options {
+	opts := s.Options
+	if kindOpts, ok := s.Ecosystems[kind]; ok {
+		opts = opts.Merge(kindOpts)
+	}
+	for _, d := range s.Directories {
+		if len(d.Kinds) > 0 && !slices.Contains(d.Kinds, kind) {
+			continue
+		}
+		if search.MatchGlob(d.Path, dir) {
+			opts = opts.Merge(d.Options)
+		}
+	}
+	return opts
+}
+
+// UpdateKeys returns the keys of the update entries that the settings set on
+// any level, a merge drops them from the entries they aren't generated for,
+// see config.WithOwnedKeys.
+func (s *Settings) UpdateKeys() []string {
+	var keys []string
+	add := func(set bool, key ...string) {
+		for _, k := range key {
+			if set && !slices.Contains(keys, k) {
+				keys = append(keys, k)
+			}
+		}
+	}
+	levels := []Options{s.Options}
+	for _, kind := range slices.Sorted(maps.Keys(s.Ecosystems)) {
+		levels = append(levels, s.Ecosystems[kind])
+	}
+	for _, d := range s.Directories {
+		levels = append(levels, d.Options)
+	}
+	for _, o := range levels {
+		add(o.Labels != nil, "labels")
+		add(o.Assignees != nil, "assignees")
+		add(o.Reviewers != nil, "reviewers")
+		add(o.Milestone != 0, "milestone")
+		add(o.OpenPullRequestsLimit != nil, "open-pull-requests-limit")
+		add(o.RebaseStrategy != "", "rebase-strategy")
+		add(o.PullRequestBranchName != nil, "pull-request-branch-name")
+		add(o.GroupPreset != "" || o.Groups != nil, "groups")
+		add(o.RulePresets != nil || o.Ignore != nil || o.Allow != nil, "ignore", "allow")
+		add(o.Cooldown != nil, "cooldown")
+	}
+	if c := s.CodeOwners; c != nil {
+		add(c.Reviewers, "reviewers")
+		add(c.Assignees, "assignees")
+		add(c.TeamLabels, "labels")
+	}
+	add(len(s.Registries) > 0, "registries", "insecure-external-code-execution")
+	return keys
+}
+
+// Merge returns o overridden by the values set in other.
+func (o Options) Merge(other Options) Options {
+	if other.Schedule != nil {
+		schedule := Schedule{}
+		if o.Schedule != nil {
+			schedule = *o.Schedule
+		}
+		schedule = schedule.merge(*other.Schedule)
+		o.Schedule = &schedule
+	}
+	if other.Labels != nil {
+		o.Labels = other.Labels
+	}
+	if other.Assignees != nil {
+		o.Assignees = other.Assignees
+	}
+	if other.Reviewers != nil {
+		o.Reviewers = other.Reviewers
+	}
+	if other.Milestone != 0 {
+		o.Milestone = other.Milestone
+	}
+	if other.TargetBranch != "" {
+		o.TargetBranch = other.TargetBranch
+	}
+	if other.OpenPullRequestsLimit != nil {
+		o.OpenPullRequestsLimit = other.OpenPullRequestsLimit
+	}
+	if other.RebaseStrategy != "" {
+		o.RebaseStrategy = other.RebaseStrategy
+	}
+	if other.PullRequestBranchName != nil {
+		o.PullRequestBranchName = other.PullRequestBranchName
+	}
+	if other.CommitMessage != nil {
+		commitMessage := config.CommitMessage{}
+		if o.CommitMessage != nil {
+			commitMessage = *o.CommitMessage
+		}
+		if other.CommitMessage.Prefix != "" {
+			commitMessage.Prefix = other.CommitMessage.Prefix
+		}
+		if other.CommitMessage.PrefixDevelopment != "" {
+			commitMessage.PrefixDevelopment = other.CommitMessage.PrefixDevelopment
+		}
+		if other.CommitMessage.Include != "" {
+			commitMessage.Include = other.CommitMessage.Include
+		}
+		o.CommitMessage = &commitMessage
+	}
+	if other.GroupPreset != "" {
+		o.GroupPreset = other.GroupPreset
+	}
+	if len(other.Groups) > 0 {
+		groups := maps.Clone(o.Groups)
+		if groups == nil {
+			groups = make(map[string]config.Group, len(other.Groups))
+		}
+		maps.Copy(groups, other.Groups)
+		o.Groups = groups
+	}
+	if other.RulePresets != nil {
+		o.RulePresets = other.RulePresets
+	}
+	if other.Ignore != nil {
+		o.Ignore = other.Ignore
+	}
+	if other.Allow != nil {
+		o.Allow = other.Allow
+	}
+	if other.Cooldown != nil {
+		cooldown := config.Cooldown{}
+		if o.Cooldown != nil {
+			cooldown = *o.Cooldown
+		}
+		if other.Cooldown.DefaultDays != nil {
+			cooldown.DefaultDays = other.Cooldown.DefaultDays
+		}
+		if other.Cooldown.SemverMajorDays != nil {
+			cooldown.SemverMajorDays = other.Cooldown.SemverMajorDays
+		}
+		if other.Cooldown.SemverMinorDays != nil {
+			cooldown.SemverMinorDays = other.Cooldown.SemverMinorDays
+		}
+		if other.Cooldown.SemverPatchDays != nil {
+			cooldown.SemverPatchDays = other.Cooldown.SemverPatchDays
+		}
+		if other.Cooldown.Include != nil {
+			cooldown.Include = other.Cooldown.Include
+		}
+		if other.Cooldown.Exclude != nil {
+			cooldown.Exclude = other.Cooldown.Exclude
+		}
+		o.Cooldown = &cooldown
+	}
+	return o
+}
+
+func (s Schedule) merge(other Schedule) Schedule {
+	if other.Interval != "" {
+		s.Interval = other.Interval
+		if other.Interval != "cron" {
+			s.Cronjob = ""
+		}
+	}
+	if other.Day != "" {
+		s.Day = other.Day
+	}
+	if other.Time != "" {
+		s.Time = other.Time
+	}
+	if other.Timezone != "" {
+		s.Timezone = other.Timezone
+	}
+	if other.Cronjob != "" {
+		s.Interval = "cron"
+		s.Cronjob = other.Cronjob
+	}
+	// A day or time set on a more specific level wins over a stagger of a
+	// less specific one.
+	if s.Stagger != nil && (other.Day != "" || other.Time != "") {
+		stagger := *s.Stagger
+		if other.Day != "" {
+			stagger.Days = nil
+		}
+		if other.Time != "" {
+			stagger.From, stagger.To = "", ""
+		}
+		s.Stagger = &stagger
+	}
+	if other.Stagger != nil {
+		s.Stagger = other.Stagger
+	}
+	return s
+}
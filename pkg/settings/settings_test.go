@@ -0,0 +1,398 @@
+package settings
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/containifyci/dependabot-templater/pkg/config"
+	"github.com/containifyci/dependabot-templater/pkg/search"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoad(t *testing.T) {
+	s, err := Load("./missing", "./testdata")
+	require.NoError(t, err)
+
+	assert.Equal(t, filepath.Join("testdata", ".dependabot-templater.yaml"), s.File)
+	assert.Equal(t, []string{"go", "npm"}, s.Kinds)
+	assert.Equal(t, search.ModeGitignore, s.ScanMode())
+	assert.Equal(t, filepath.Join("testdata", ".github/dependabot.yml"), s.OutputPath())
+}
+
+func TestLoadWithoutFile(t *testing.T) {
+	s, err := Load(t.TempDir())
+	require.NoError(t, err)
+	assert.Equal(t, &Settings{}, s)
+	assert.Equal(t, search.ModeAll, s.ScanMode())
+	assert.True(t, s.Included("anything"))
+}
+
+func TestResolve(t *testing.T) {
+	s, err := LoadFile("./testdata/.dependabot-templater.yaml")
+	require.NoError(t, err)
+
+	for _, test := range []struct {
+		kind     string
+		dir      string
+		expected Schedule
+	}{
+		{"go", "services/api", Schedule{Interval: "weekly", Day: "monday", Time: "09:00", Timezone: "Europe/Berlin"}},
+		{"npm", "services/api", Schedule{Interval: "daily", Day: "monday", Time: "09:00", Timezone: "Europe/Berlin"}},
+		{"go", "services/payments/gateway", Schedule{Interval: "weekly", Day: "friday", Time: "09:00", Timezone: "Europe/Berlin"}},
+		{"go", "services/web", Schedule{Interval: "weekly", Day: "monday", Time: "09:00", Timezone: "Europe/Berlin"}},
+		{"npm", "services/web", Schedule{Interval: "monthly", Day: "monday", Time: "09:00", Timezone: "Europe/Berlin"}},
+		{"go", "services/tokyo/api", Schedule{Interval: "weekly", Day: "monday", Time: "09:00", Timezone: "Asia/Tokyo"}},
+		{"npm", "services/batch", Schedule{Interval: "cron", Day: "monday", Time: "09:00", Timezone: "Europe/Berlin", Cronjob: "0 3 * * 1-5"}},
+	} {
+		t.Run(test.kind+" "+test.dir, func(t *testing.T) {
+			assert.Equal(t, test.expected, *s.Resolve(test.kind, test.dir).Schedule)
+		})
+	}
+}
+
+func TestResolveOptions(t *testing.T) {
+	limit := 2
+	s := &Settings{
+		Options: Options{
+			Labels:        []string{"dependencies"},
+			Reviewers:     []string{"platform"},
+			CommitMessage: &config.CommitMessage{Prefix: "deps", Include: "scope"},
+		},
+		Ecosystems: map[string]Options{
+			"npm": {Labels: []string{"javascript"}, OpenPullRequestsLimit: &limit},
+		},
+		Directories: []Directory{
+			{Path: "web/**", Options: Options{TargetBranch: "develop", CommitMessage: &config.CommitMessage{PrefixDevelopment: "dev"}}},
+		},
+	}
+	assert.Equal(t, Options{
+		Labels:                []string{"javascript"},
+		Reviewers:             []string{"platform"},
+		TargetBranch:          "develop",
+		OpenPullRequestsLimit: &limit,
+		CommitMessage:         &config.CommitMessage{Prefix: "deps", PrefixDevelopment: "dev", Include: "scope"},
+	}, s.Resolve("npm", "web/app"))
+	assert.Equal(t, s.Options, s.Resolve("go", "api"))
+}
+
+func TestResolveCooldown(t *testing.T) {
+	days := func(n int) *int { return &n }
+	s := &Settings{
+		Options: Options{Cooldown: &config.Cooldown{DefaultDays: days(3), Exclude: []string{"@company/*"}}},
+		Ecosystems: map[string]Options{
+			"npm": {Cooldown: &config.Cooldown{SemverMajorDays: days(30), Include: []string{"*"}}},
+		},
+		Directories: []Directory{
+			{Path: "web/**", Options: Options{Cooldown: &config.Cooldown{DefaultDays: days(7)}}},
+			{Path: "legacy", Options: Options{Cooldown: &config.Cooldown{DefaultDays: days(0)}}},
+		},
+	}
+	assert.Equal(t, &config.Cooldown{DefaultDays: days(7), SemverMajorDays: days(30), Include: []string{"*"}, Exclude: []string{"@company/*"}}, s.Resolve("npm", "web/app").Cooldown)
+	assert.Equal(t, &config.Cooldown{DefaultDays: days(0), SemverMajorDays: days(30), Include: []string{"*"}, Exclude: []string{"@company/*"}}, s.Resolve("npm", "legacy").Cooldown)
+	assert.Equal(t, s.Options.Cooldown, s.Resolve("go", "api").Cooldown)
+}
+
+func TestResolveStagger(t *testing.T) {
+	stagger := &Stagger{Days: []string{"monday", "tuesday"}, From: "08:00", To: "10:00"}
+	s := &Settings{
+		Options: Options{Schedule: &Schedule{Interval: "weekly", Stagger: stagger}},
+		Directories: []Directory{
+			{Path: "services/**", Options: Options{Schedule: &Schedule{Day: "friday"}}},
+		},
+	}
+	assert.Equal(t, stagger, s.Resolve("go", "tools").Schedule.Stagger)
+	assert.Equal(t, Schedule{Interval: "weekly", Day: "friday", Stagger: &Stagger{From: "08:00", To: "10:00"}}, *s.Resolve("go", "services/api").Schedule)
+}
+
+func TestIncluded(t *testing.T) {
+	s := &Settings{Include: []string{"services/**"}, Exclude: []string{"services/legacy/**"}}
+	assert.True(t, s.Included("services/api"))
+	assert.False(t, s.Included("services/legacy/billing"))
+	assert.False(t, s.Included("tools"))
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, test := range []struct {
+		name     string
+		config   string
+		expected string
+	}{
+		{
+			name:     "unknown field",
+			config:   "kinds: [go]\nschedules:\n  interval: daily\n",
+			expected: "config.yaml:2: field schedules not found in type settings.Settings",
+		},
+		{
+			name:     "wrong type",
+			config:   "kinds: go\n",
+			expected: "config.yaml:1: cannot unmarshal !!str `go` into []string",
+		},
+		{
+			name:     "invalid yaml",
+			config:   "kinds: [go\n",
+			expected: "config.yaml:1: did not find expected ',' or ']'",
+		},
+		{
+			name: "invalid values",
+			config: `scan: everything
+schedule:
+  interval: hourly
+ecosystems:
+  npm:
+    schedule:
+      interval: monthly
+      day: monday
+directories:
+  - kinds: [go]
+    schedule:
+      day: someday
+`,
+			expected: `config.yaml:1: scan: unknown scan mode "everything", expected one of [all gitignore git-index]
+config.yaml:3: schedule.interval: unknown interval "hourly", expected one of [daily weekly monthly quarterly semiannually yearly cron]
+config.yaml:8: ecosystems.npm.schedule.day: day is only supported for the weekly interval
+config.yaml:10: directories[0]: path is required
+config.yaml:12: directories[0].schedule.day: unknown day "someday", expected one of [monday tuesday wednesday thursday friday saturday sunday]`,
+		},
+		{
+			name: "invalid schedule",
+			config: `schedule:
+  time: "25:00"
+  timezone: CEST
+ecosystems:
+  go:
+    schedule:
+      interval: daily
+      cronjob: "0 9 * * *"
+directories:
+  - path: services/batch
+    schedule:
+      cronjob: "@daily"
+`,
+			expected: `config.yaml:2: schedule.time: time "25:00" must be formatted as hh:mm
+config.yaml:3: schedule.timezone: unknown time zone "CEST", expected an IANA name like Europe/Berlin
+config.yaml:8: ecosystems.go.schedule.cronjob: cronjob is only supported for the cron interval
+config.yaml:12: directories[0].schedule.cronjob: cronjob "@daily" must have 5 fields, minute hour day-of-month month day-of-week`,
+		},
+		{
+			name: "invalid stagger",
+			config: `schedule:
+  interval: daily
+  stagger:
+    days: [monday, funday]
+    from: "18:00"
+    to: "08:00"
+ecosystems:
+  go:
+    schedule:
+      time: "09:00"
+      stagger:
+        from: "10:00"
+        step: -5
+  npm:
+    schedule:
+      stagger: {}
+`,
+			expected: `config.yaml:4: schedule.stagger.days: days are only supported for the weekly interval
+config.yaml:4: schedule.stagger.days[1]: unknown day "funday", expected one of [monday tuesday wednesday thursday friday saturday sunday]
+config.yaml:6: schedule.stagger.to: to 08:00 is before from 18:00
+config.yaml:12: ecosystems.go.schedule.stagger: from and to must be set together
+config.yaml:12: ecosystems.go.schedule.stagger.from: from and to can't be combined with the time of the schedule
+config.yaml:13: ecosystems.go.schedule.stagger.step: step must be between 1 and 1440 minutes
+config.yaml:16: ecosystems.npm.schedule.stagger: days or from and to are required`,
+		},
+		{
+			name: "invalid update options",
+			config: `milestone: -1
+open-pull-requests-limit: -2
+rebase-strategy: always
+pull-request-branch-name:
+  separator: "+"
+commit-message:
+  include: all
+`,
+			expected: `config.yaml:1: milestone: milestone must be a positive number
+config.yaml:2: open-pull-requests-limit: open-pull-requests-limit must not be negative
+config.yaml:3: rebase-strategy: unknown rebase-strategy "always", expected one of [auto disabled]
+config.yaml:5: pull-request-branch-name.separator: unknown separator "+", expected one of [- _ /]
+config.yaml:7: commit-message.include: include only supports scope`,
+		},
+		{
+			name: "invalid groups",
+			config: `group-preset: everything
+ecosystems:
+  npm:
+    groups:
+      "aws sdk":
+        patterns: ["@aws-sdk/*"]
+      empty: {}
+      dev:
+        dependency-type: dev
+        update-types: [feature]
+`,
+			expected: `config.yaml:1: group-preset: unknown group-preset "everything", expected one of [minor all-in-one split-major per-scope none]
+config.yaml:6: ecosystems.npm.groups.aws sdk: invalid group name "aws sdk", only letters, digits, pipes, underscores and hyphens are allowed
+config.yaml:7: ecosystems.npm.groups.empty: group needs at least one of patterns, exclude-patterns, dependency-type or update-types
+config.yaml:9: ecosystems.npm.groups.dev.dependency-type: expected development or production
+config.yaml:10: ecosystems.npm.groups.dev.update-types[0]: unknown update-type "feature", expected one of [major minor patch]`,
+		},
+		{
+			name: "invalid rules",
+			config: `rule-presets: [majors-only, patches-only]
+directories:
+  - path: services/**
+    ignore:
+      - versions: [">= 1.0"]
+        update-types: [version-update:semver-feature]
+    allow:
+      - dependency-type: dev
+`,
+			expected: `config.yaml:1: rule-presets[1]: unknown rule preset "patches-only", expected one of [security-only majors-only ignore-go-toolchain ignore-types-majors]
+config.yaml:5: directories[0].ignore[0]: dependency-name is required
+config.yaml:6: directories[0].ignore[0].update-types[0]: unknown update-type "version-update:semver-feature", expected one of [version-update:semver-major version-update:semver-minor version-update:semver-patch]
+config.yaml:8: directories[0].allow[0].dependency-type: unknown dependency-type "dev", expected one of [direct indirect all production development]`,
+		},
+		{
+			name: "invalid cooldown",
+			config: `cooldown:
+  default-days: 120
+ecosystems:
+  npm:
+    cooldown:
+      include: ["*"]
+`,
+			expected: `config.yaml:2: cooldown.default-days: default-days must be between 0 and 90`,
+		},
+		{
+			name:     "invalid glob",
+			config:   "exclude: [\"legacy/[a\"]\n",
+			expected: `config.yaml:1: exclude[0]: invalid glob "legacy/[a": syntax error in pattern`,
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := Parse("config.yaml", []byte(test.config))
+			var errs Errors
+			require.ErrorAs(t, err, &errs)
+			assert.Equal(t, test.expected, err.Error())
+		})
+	}
+}
+
+func TestUpdateKeys(t *testing.T) {
+	s, err := Parse("config.yaml", []byte(`labels: [dependencies]
+codeowners:
+  reviewers: true
+ecosystems:
+  terraform:
+    rule-presets: []
+directories:
+  - path: services/**
+    cooldown:
+      default-days: 3
+`))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"labels", "ignore", "allow", "cooldown", "reviewers"}, s.UpdateKeys())
+	assert.Empty(t, (&Settings{}).UpdateKeys())
+}
+
+func TestCheckKinds(t *testing.T) {
+	s, err := Parse("config.yaml", []byte(`kinds: [go, golang, all]
+ecosystems:
+  go:
+    labels: [go]
+  gomod:
+    labels: [go]
+directories:
+  - path: services/**
+    kinds: [npm, node]
+`))
+	require.NoError(t, err)
+	assert.NoError(t, s.CheckKinds(map[string]string{"go": "gomod", "golang": "gomod", "gomod": "gomod", "npm": "npm", "node": "npm"}))
+
+	err = s.CheckKinds(map[string]string{"go": "gomod", "npm": "npm"})
+	var errs Errors
+	require.ErrorAs(t, err, &errs)
+	assert.Equal(t, `config.yaml:1: kinds[1]: unknown kind "golang", expected one of [go npm]
+config.yaml:6: ecosystems.gomod: unknown kind "gomod", expected one of [go npm]
+config.yaml:9: directories[0].kinds[1]: unknown kind "node", expected one of [go npm]`, err.Error())
+
+	assert.NoError(t, (&Settings{}).CheckKinds(nil))
+
+	s, err = Parse("config.yaml", []byte(`cooldown:
+  semver-major-days: 30
+ecosystems:
+  docker:
+    cooldown:
+      semver-major-days: 7
+  npm:
+    cooldown:
+      semver-major-days: 7
+  submodules:
+    cooldown:
+      default-days: 3
+directories:
+  - path: infra/**
+    kinds: [docker]
+    cooldown:
+      default-days: 3
+      semver-minor-days: 0
+  - path: web/**
+    cooldown:
+      semver-patch-days: 1
+`))
+	require.NoError(t, err)
+	err = s.CheckKinds(map[string]string{"docker": "docker", "npm": "npm", "submodules": "gitsubmodule"})
+	require.ErrorAs(t, err, &errs)
+	assert.Equal(t, `config.yaml:6: ecosystems.docker.cooldown.semver-major-days: semver-major-days is not supported for docker, use default-days
+config.yaml:12: ecosystems.submodules.cooldown: cooldown is not supported for gitsubmodule`, err.Error())
+}
+
+func TestParseEmpty(t *testing.T) {
+	s, err := Parse("config.yaml", nil)
+	require.NoError(t, err)
+	assert.Equal(t, &Settings{}, s)
+}
+
+func TestRegistriesFor(t *testing.T) {
+	s, err := Parse("config.yaml", []byte(`registries:
+  npm-private:
+    type: npm-registry
+    url: https://npm.example.com
+    kinds: [npm]
+  git-modules:
+    type: git
+    url: https://github.com
+    directories: ["infra/**"]
+  npm-legacy:
+    type: npm-registry
+    url: https://legacy.example.com
+    kinds: [npm]
+    directories: ["legacy/**"]
+`))
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"npm-private"}, s.RegistriesFor("npm", "npm", "web"))
+	assert.Equal(t, []string{"npm-legacy", "npm-private"}, s.RegistriesFor("npm", "npm", "legacy/web"))
+	assert.Equal(t, []string{"git-modules"}, s.RegistriesFor("terraform", "terraform", "infra/prod"))
+	assert.Empty(t, s.RegistriesFor("docker", "docker", "web"))
+}
+
+func TestRegistryValidation(t *testing.T) {
+	_, err := Parse("config.yaml", []byte(`registries:
+  broken:
+    type: artifactory
+    url: https://example.com
+  hex:
+    type: hex-organization
+  maven:
+    type: maven-repository
+  "npm #private":
+    type: npm-registry
+    url: https://example.com
+`))
+	assert.EqualError(t, err, `config.yaml:3: registries.broken.type: unknown registry type "artifactory"
+config.yaml:6: registries.hex: organization is required for hex-organization
+config.yaml:8: registries.maven: url is required for maven-repository
+config.yaml:10: registries.npm #private: invalid registry name "npm #private", only letters, digits, dots, underscores and hyphens are allowed`)
+}
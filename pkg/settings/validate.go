@@ -0,0 +1,294 @@
+package settings
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/containifyci/dependabot-templater/internal/yamlpath"
+	"github.com/containifyci/dependabot-templater/pkg/config"
+	"github.com/containifyci/dependabot-templater/pkg/search"
+
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	ScanModes = []string{"all", "gitignore", "git-index"}
+	Backends  = []string{"yaml", "template"}
+	Stales    = []string{string(config.StaleRemove), string(config.StaleMark)}
+	// GroupPresets are the built-in groups of group-preset.
+	GroupPresets = []string{"minor", "all-in-one", "split-major", "per-scope", "none"}
+	// RulePresets are the built-in ignore and allow rules of rule-presets.
+	RulePresets = []string{"security-only", "majors-only", "ignore-go-toolchain", "ignore-types-majors"}
+)
+
+// Error is a single problem of a config file.
+type Error = config.Error
+
+// Errors are all problems found in a config file.
+type Errors = config.Errors
+
+type validator struct {
+	file string
+	root *yaml.Node
+	errs Errors
+}
+
+// add records a problem of the value at path, path elements are mapping keys
+// or sequence indexes.
+func (v *validator) add(path []any, format string, args ...any) {
+	v.errs = append(v.errs, Error{
+		File: v.file,
+		Line: yamlpath.Line(v.root, path),
+		Path: yamlpath.Format(path),
+		Msg:  fmt.Sprintf(format, args...),
+	})
+}
+
+func (s *Settings) validate(file string, root *yaml.Node) Errors {
+	v := &validator{file: file, root: root}
+	if s.Scan != "" && !slices.Contains(ScanModes, s.Scan) {
+		v.add([]any{"scan"}, "unknown scan mode %q, expected one of %v", s.Scan, ScanModes)
+	}
+	if s.Backend != "" && !slices.Contains(Backends, s.Backend) {
+		v.add([]any{"backend"}, "unknown backend %q, expected one of %v", s.Backend, Backends)
+	}
+	if s.Stale != "" && !slices.Contains(Stales, s.Stale) {
+		v.add([]any{"stale"}, "unknown stale mode %q, expected one of %v", s.Stale, Stales)
+	}
+	for i, glob := range s.Include {
+		v.glob([]any{"include", i}, glob)
+	}
+	for i, glob := range s.Exclude {
+		v.glob([]any{"exclude", i}, glob)
+	}
+	v.options(nil, s.Options)
+	for name, r := range s.Registries {
+		if err := config.ValidateRegistryName(name); err != nil {
+			v.add([]any{"registries", name}, "%s", err)
+		}
+		v.registry([]any{"registries", name}, r)
+	}
+	for kind, opts := range s.Ecosystems {
+		v.options([]any{"ecosystems", kind}, opts)
+	}
+	for i, dir := range s.Directories {
+		path := []any{"directories", i}
+		if dir.Path == "" {
+			v.add(path, "path is required")
+		}
+		v.glob(append(path, "path"), dir.Path)
+		v.options(path, dir.Options)
+	}
+	slices.SortStableFunc(v.errs, func(a, b Error) int { return a.Line - b.Line })
+	return v.errs
+}
+
+// CheckKinds reports the entries of kinds, the keys of ecosystems and the
+// kinds of directories that aren't one of the kinds of ecosystems, like Parse
+// reports the other problems. A cooldown of a kind, or of a directory limited
+// to kinds, must be supported by the ecosystem of the kind, see
+// config.Cooldown.Unsupported. The kinds are registered by the generator, so
+// they are only known after parsing.
+func (s *Settings) CheckKinds(ecosystems map[string]string) error {
+	v := &validator{}
+	if s.doc != nil {
+		v.file, v.root = s.doc.file, s.doc.root
+	}
+	known := slices.Sorted(maps.Keys(ecosystems))
+	kind := func(path []any, kind string) bool {
+		if _, ok := ecosystems[kind]; !ok {
+			v.add(path, "unknown kind %q, expected one of %v", kind, known)
+			return false
+		}
+		return true
+	}
+	cooldown := func(path []any, c *config.Cooldown, kind string) {
+		if c == nil {
+			return
+		}
+		for _, p := range c.Unsupported(ecosystems[kind]) {
+			v.add(append(append(path, "cooldown"), p.Path...), "%s", p.Msg)
+		}
+	}
+	for i, k := range s.Kinds {
+		if k != "all" {
+			kind([]any{"kinds", i}, k)
+		}
+	}
+	for _, k := range slices.Sorted(maps.Keys(s.Ecosystems)) {
+		if kind([]any{"ecosystems", k}, k) {
+			cooldown([]any{"ecosystems", k}, s.Ecosystems[k].Cooldown, k)
+		}
+	}
+	for i, dir := range s.Directories {
+		for j, k := range dir.Kinds {
+			if kind([]any{"directories", i, "kinds", j}, k) {
+				cooldown([]any{"directories", i}, dir.Cooldown, k)
+			}
+		}
+	}
+	if len(v.errs) == 0 {
+		return nil
+	}
+	slices.SortStableFunc(v.errs, func(a, b Error) int { return a.Line - b.Line })
+	return v.errs
+}
+
+func (v *validator) glob(path []any, glob string) {
+	if err := search.ValidateGlob(glob); err != nil {
+		v.add(path, "invalid glob %q: %s", glob, err)
+	}
+}
+
+func (v *validator) registry(path []any, r Registry) {
+	if _, ok := config.RegistryTypes[r.Type]; !ok {
+		v.add(append(path, "type"), "unknown registry type %q", r.Type)
+	}
+	switch r.Type {
+	case "hex-organization":
+		if r.Organization == "" {
+			v.add(path, "organization is required for %s", r.Type)
+		}
+	default:
+		if r.URL == "" {
+			v.add(path, "url is required for %s", r.Type)
+		}
+	}
+	for i, glob := range r.Directories {
+		v.glob(append(path, "directories", i), glob)
+	}
+}
+
+func (v *validator) options(path []any, opts Options) {
+	if opts.Schedule != nil {
+		v.schedule(append(path, "schedule"), opts.Schedule)
+	}
+	if opts.Milestone < 0 {
+		v.add(append(path, "milestone"), "milestone must be a positive number")
+	}
+	if opts.OpenPullRequestsLimit != nil && *opts.OpenPullRequestsLimit < 0 {
+		v.add(append(path, "open-pull-requests-limit"), "open-pull-requests-limit must not be negative")
+	}
+	if opts.RebaseStrategy != "" && !slices.Contains(config.RebaseStrategies, opts.RebaseStrategy) {
+		v.add(append(path, "rebase-strategy"), "unknown rebase-strategy %q, expected one of %v", opts.RebaseStrategy, config.RebaseStrategies)
+	}
+	if opts.PullRequestBranchName != nil && !slices.Contains(config.BranchSeparators, opts.PullRequestBranchName.Separator) {
+		v.add(append(path, "pull-request-branch-name", "separator"), "unknown separator %q, expected one of %v", opts.PullRequestBranchName.Separator, config.BranchSeparators)
+	}
+	if opts.CommitMessage != nil && opts.CommitMessage.Include != "" && opts.CommitMessage.Include != "scope" {
+		v.add(append(path, "commit-message", "include"), "include only supports scope")
+	}
+	if opts.GroupPreset != "" && !slices.Contains(GroupPresets, opts.GroupPreset) {
+		v.add(append(path, "group-preset"), "unknown group-preset %q, expected one of %v", opts.GroupPreset, GroupPresets)
+	}
+	for _, name := range slices.Sorted(maps.Keys(opts.Groups)) {
+		for _, p := range opts.Groups[name].Problems(name) {
+			v.add(append(append(path, "groups", name), p.Path...), "%s", p.Msg)
+		}
+	}
+	for i, preset := range opts.RulePresets {
+		if !slices.Contains(RulePresets, preset) {
+			v.add(append(path, "rule-presets", i), "unknown rule preset %q, expected one of %v", preset, RulePresets)
+		}
+	}
+	// The versions are checked with the ecosystem once the entries are
+	// generated.
+	for i, ignore := range opts.Ignore {
+		for _, p := range ignore.Problems("") {
+			v.add(append(append(path, "ignore", i), p.Path...), "%s", p.Msg)
+		}
+	}
+	for i, allow := range opts.Allow {
+		for _, p := range allow.Problems() {
+			v.add(append(append(path, "allow", i), p.Path...), "%s", p.Msg)
+		}
+	}
+	// The support of the ecosystem is checked with the kinds, see CheckKinds.
+	if opts.Cooldown != nil {
+		for _, p := range opts.Cooldown.Problems("") {
+			v.add(append(append(path, "cooldown"), p.Path...), "%s", p.Msg)
+		}
+	}
+}
+
+func (v *validator) schedule(path []any, s *Schedule) {
+	if s.Interval != "" && !slices.Contains(config.Intervals, s.Interval) {
+		v.add(append(path, "interval"), "unknown interval %q, expected one of %v", s.Interval, config.Intervals)
+	}
+	if s.Day != "" {
+		if !slices.Contains(config.Days, s.Day) {
+			v.add(append(path, "day"), "unknown day %q, expected one of %v", s.Day, config.Days)
+		} else if s.Interval != "" && s.Interval != "weekly" {
+			v.add(append(path, "day"), "day is only supported for the weekly interval")
+		}
+	}
+	if s.Time != "" {
+		if err := config.ValidateTime(s.Time); err != nil {
+			v.add(append(path, "time"), "%s", err)
+		}
+	}
+	if s.Timezone != "" {
+		if err := config.ValidateTimezone(s.Timezone); err != nil {
+			v.add(append(path, "timezone"), "%s", err)
+		}
+	}
+	switch {
+	case s.Interval == "cron" && s.Cronjob == "":
+		v.add(append(path, "cronjob"), "cronjob is required for the cron interval")
+	case s.Cronjob == "":
+	case s.Interval != "" && s.Interval != "cron":
+		v.add(append(path, "cronjob"), "cronjob is only supported for the cron interval")
+	default:
+		if err := config.ValidateCron(s.Cronjob); err != nil {
+			v.add(append(path, "cronjob"), "%s", err)
+		}
+	}
+	if s.Stagger != nil {
+		v.stagger(append(path, "stagger"), s)
+	}
+}
+
+func (v *validator) stagger(path []any, s *Schedule) {
+	st := s.Stagger
+	if s.Interval == "cron" || s.Cronjob != "" {
+		v.add(path, "stagger is not supported for the cron interval")
+	}
+	if len(st.Days) == 0 && st.From == "" && st.To == "" {
+		v.add(path, "days or from and to are required")
+	}
+	if len(st.Days) > 0 {
+		if s.Day != "" {
+			v.add(append(path, "days"), "days can't be combined with the day of the schedule")
+		}
+		if s.Interval != "" && s.Interval != "weekly" {
+			v.add(append(path, "days"), "days are only supported for the weekly interval")
+		}
+	}
+	for i, day := range st.Days {
+		if !slices.Contains(config.Days, day) {
+			v.add(append(path, "days", i), "unknown day %q, expected one of %v", day, config.Days)
+		}
+	}
+	if (st.From == "") != (st.To == "") {
+		v.add(path, "from and to must be set together")
+	}
+	for _, bound := range []struct{ key, value string }{{"from", st.From}, {"to", st.To}} {
+		if bound.value == "" {
+			continue
+		}
+		if err := config.ValidateTime(bound.value); err != nil {
+			v.add(append(path, bound.key), "%s", err)
+		}
+	}
+	if st.From != "" && s.Time != "" {
+		v.add(append(path, "from"), "from and to can't be combined with the time of the schedule")
+	}
+	// hh:mm strings compare like the times they represent.
+	if config.ValidateTime(st.From) == nil && config.ValidateTime(st.To) == nil && st.From > st.To {
+		v.add(append(path, "to"), "to %s is before from %s", st.To, st.From)
+	}
+	if st.Step < 0 || st.Step > 24*60 {
+		v.add(append(path, "step"), "step must be between 1 and %d minutes", 24*60)
+	}
+}
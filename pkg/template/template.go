@@ -2,14 +2,36 @@ package template
 
 import (
 	"embed"
+	"encoding/json"
 	"fmt"
 	"strings"
+	"sync"
 	"text/template"
 )
 
 //go:embed *.tmpl
 var templates embed.FS
 
+var (
+	registeredMu sync.RWMutex
+	registered   = map[string]string{}
+)
+
+// Register makes a template available under name. Registered templates take
+// precedence over the embedded ones.
+func Register(name, content string) {
+	registeredMu.Lock()
+	defer registeredMu.Unlock()
+	registered[name] = content
+}
+
+// Unregister removes a template added with Register.
+func Unregister(name string) {
+	registeredMu.Lock()
+	defer registeredMu.Unlock()
+	delete(registered, name)
+}
+
 // Custom function to indent YAML lines
 func indentYAML(spaces int, yamlStr string) string {
 	indentation := strings.Repeat(" ", spaces)
@@ -21,95 +43,220 @@ func indentYAML(spaces int, yamlStr string) string {
 }
 
 func readTemplate(name string) string {
-	data, err := templates.ReadFile(name)
+	data, err := loadTemplate(name)
 	if err != nil {
 		fmt.Printf("Error reading template file %s\n", name)
 		panic(err)
 	}
-	return string(data)
+	return data
+}
+
+func loadTemplate(name string) (string, error) {
+	registeredMu.RLock()
+	content, ok := registered[name]
+	registeredMu.RUnlock()
+	if ok {
+		return content, nil
+	}
+	data, err := templates.ReadFile(name)
+	if err != nil {
+		return "", fmt.Errorf("reading template %s: %w", name, err)
+	}
+	return string(data), nil
+}
+
+func parseTemplate(name string, funcMap template.FuncMap) (*template.Template, error) {
+	data, err := loadTemplate(name)
+	if err != nil {
+		return nil, err
+	}
+	return template.New(name).Funcs(funcMap).Parse(data)
 }
 
-func RenderHeader(kinds []string) (string, error) {
+// partialsTemplate defines the parts shared by the ecosystem templates.
+const partialsTemplate = "dependabot-partials.yml.tmpl"
+
+// parseEntryTemplate parses the ecosystem template name together with the
+// shared partials.
+func parseEntryTemplate(name string) (*template.Template, error) {
+	tmpl, err := parseTemplate(partialsTemplate, template.FuncMap{"quote": quote})
+	if err != nil {
+		return nil, err
+	}
+	data, err := loadTemplate(name)
+	if err != nil {
+		return nil, err
+	}
+	return tmpl.New(name).Parse(data)
+}
+
+// Registry is a private registry rendered into the header.
+type Registry struct {
+	Name                 string
+	Type                 string
+	URL                  string
+	Username             string
+	Password             string
+	Key                  string
+	Token                string
+	Organization         string
+	Repo                 string
+	AuthKey              string
+	PublicKeyFingerprint string
+	ReplacesBase         bool
+}
+
+type header struct {
+	Registries []Registry
+}
+
+func RenderHeader(registries []Registry) (string, error) {
 	var tpl strings.Builder
 	funcMap := template.FuncMap{
 		"indent": indentYAML,
+		"quote":  quote,
 	}
-	tmpl := template.Must(template.New("dependabot-header.yml.tmpl").Funcs(funcMap).Parse(readTemplate("dependabot-header.yml.tmpl")))
-
-	var regs strings.Builder
-	for _, kind := range kinds {
-		if reg, ok := registries[kind]; ok {
-			regs.WriteString(reg)
-		}
+	tmpl, err := parseTemplate("dependabot-header.yml.tmpl", funcMap)
+	if err != nil {
+		return "", err
 	}
 
-	entry := DependaBotEntry{
-		Registries: regs.String(),
-	}
-	err := tmpl.Execute(&tpl, entry)
+	err = tmpl.Execute(&tpl, header{Registries: registries})
 	if err != nil {
 		return "", err
 	}
 	return tpl.String(), nil
 }
 
+// quote renders s as double quoted YAML string.
+func quote(s string) string {
+	var b strings.Builder
+	enc := json.NewEncoder(&b)
+	// Version ranges like >=1.0 <2 stay readable.
+	enc.SetEscapeHTML(false)
+	_ = enc.Encode(s)
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
 type DependaBotResult struct {
-	Folders  []string
-	Template string
-	Registry string
-	Interval string
-	Day      string
+	Folders   []string
+	Template  string
+	Ecosystem string
+	Registry  string
+	Interval  string
+	Day       string
+	// Files are the matched files that triggered the detection of Folders.
+	Files []string
+	// Entries are rendered instead of the entries built from Folders when set.
+	Entries []DependaBotEntry
 }
 
 type DependaBotEntry struct {
 	Directory  string
-	Registries string
+	Registries []string
 	Interval   string
 	Day        string
+	Time       string
+	Timezone   string
+	Cronjob    string
+
+	Labels    []string
+	Assignees []string
+	Reviewers []string
+	Milestone int
+	// TargetBranch is the branch the pull requests are opened against.
+	TargetBranch string
+	// OpenPullRequestsLimit overrides the default limit when set, 0 disables
+	// version updates.
+	OpenPullRequestsLimit *int
+	// RebaseStrategy overrides the default strategy when set.
+	RebaseStrategy      string
+	BranchNameSeparator string
+	// CommitMessageInclude defaults to scope.
+	CommitMessagePrefix            string
+	CommitMessagePrefixDevelopment string
+	CommitMessageInclude           string
+
+	// Groups, Ignore and Allow are rendered as they are, the defaults of the
+	// ecosystems are resolved by the generator.
+	Groups []Group
+	Ignore []Ignore
+	Allow  []Allow
+
+	Cooldown *Cooldown
+}
+
+// Group is a dependency group of an entry.
+type Group struct {
+	Name            string
+	AppliesTo       string
+	DependencyType  string
+	Patterns        []string
+	ExcludePatterns []string
+	UpdateTypes     []string
+}
+
+// Ignore is an ignore rule of an entry.
+type Ignore struct {
+	DependencyName string
+	Versions       []string
+	UpdateTypes    []string
+}
+
+// Allow is an allow rule of an entry.
+type Allow struct {
+	DependencyName string
+	DependencyType string
+}
+
+// Cooldown delays the updates of an entry after a release.
+type Cooldown struct {
+	DefaultDays     int
+	SemverMajorDays int
+	SemverMinorDays int
+	SemverPatchDays int
+	Include         []string
+	Exclude         []string
 }
 
 func RenderDependaBot(result DependaBotResult) (string, error) {
 	var tpl strings.Builder
 	var entries = make([]DependaBotEntry, 0)
-	
-	// Set default values if not provided for backward compatibility
-	interval := result.Interval
-	if interval == "" {
-		interval = "weekly"
+
+	if result.Entries != nil {
+		entries = append(entries, result.Entries...)
+	} else {
+		var registries []string
+		if result.Registry != "" {
+			registries = []string{result.Registry}
+		}
+		for _, folder := range result.Folders {
+			entries = append(entries, DependaBotEntry{
+				Directory:  folder,
+				Registries: registries,
+				Interval:   result.Interval,
+				Day:        result.Day,
+			})
+		}
 	}
-	
-	day := result.Day
-	if day == "" && interval == "weekly" {
-		day = "sunday"
+
+	// Set default values if not provided for backward compatibility
+	for i := range entries {
+		if entries[i].Interval == "" {
+			entries[i].Interval = "weekly"
+		}
+		if entries[i].Day == "" && entries[i].Interval == "weekly" {
+			entries[i].Day = "sunday"
+		}
 	}
-	
-	for _, folder := range result.Folders {
-		entries = append(entries, DependaBotEntry{
-			Directory:  folder, 
-			Registries: result.Registry,
-			Interval:   interval,
-			Day:        day,
-		})
+
+	tmpl, err := parseEntryTemplate(result.Template)
+	if err != nil {
+		return "", err
 	}
-	tmpl := template.Must(template.New(result.Template).Parse(readTemplate(result.Template)))
-	err := tmpl.Execute(&tpl, entries)
+	err = tmpl.Execute(&tpl, entries)
 	if err != nil {
 		return "", err
 	}
 	return tpl.String(), nil
 }
-
-var registries map[string]string = map[string]string{
-	"npm": `npm-registry:
-  type: npm-registry
-  url: https://europe-west3-npm.pkg.dev/xxxxxxx/npm-registry
-  username: "_json_key_base64" # <- Note the username
-  password: ${{ secrets.ARTIFACTORY_REGISTRY_SERVICE_ACCOUNT_KEY_BASE64 }} # base64 encoded service account key stored as Github secret. SA must have reader permissions in npm repository.
-`,
-	"python": `python-registry:
-  type: python-index
-  url: https://europe-west3-python.pkg.dev/xxxxxxx/python-registry
-  username: "_json_key_base64" # <- Note the username
-  password: ${{ secrets.ARTIFACTORY_REGISTRY_SERVICE_ACCOUNT_KEY_BASE64 }} # base64 encoded service account key stored as Github secret. SA must have reader permissions in npm repository.
-`,
-}
@@ -78,6 +78,7 @@ func TestReadTemplate(t *testing.T) {
 func TestRenderHeader(t *testing.T) {
 	for _, test := range []struct {
 		kind             string
+		registries       []Registry
 		expectedTemplate string
 	}{
 		{
@@ -85,20 +86,30 @@ func TestRenderHeader(t *testing.T) {
 			expectedTemplate: HeaderTerraformConfig(),
 		},
 		{
-			kind:             "npm",
+			kind: "npm",
+			registries: []Registry{{
+				Name:     "npm-registry",
+				Type:     "npm-registry",
+				URL:      "https://europe-west3-npm.pkg.dev/xxxxxxx/npm-registry",
+				Username: "_json_key_base64",
+				Password: "${{ secrets.ARTIFACTORY_REGISTRY_SERVICE_ACCOUNT_KEY_BASE64 }}",
+			}},
 			expectedTemplate: HeaderNodeJSConfig(),
 		},
 	} {
 		t.Run(test.kind, func(t *testing.T) {
-			tmpl, err := RenderHeader([]string{test.kind})
+			tmpl, err := RenderHeader(test.registries)
 			require.NoError(t, err)
 			assert.Equal(t, test.expectedTemplate, tmpl)
 		})
 	}
-	kinds := []string{"npm", "terraform"}
-	output, err := RenderHeader(kinds)
+	output, err := RenderHeader([]Registry{
+		{Name: "hex", Type: "hex-organization", Organization: "acme", Key: "${{ secrets.HEX_KEY }}"},
+		{Name: "npm", Type: "npm-registry", URL: "https://npm.pkg.github.com", Token: "${{ secrets.NPM_TOKEN }}", ReplacesBase: true},
+	})
 	assert.Nil(t, err)
-	assert.NotNil(t, output)
+	assert.Contains(t, output, "  hex:\n    type: hex-organization\n    key: \"${{ secrets.HEX_KEY }}\"\n    organization: \"acme\"\n")
+	assert.Contains(t, output, "    token: \"${{ secrets.NPM_TOKEN }}\"\n    replaces-base: true\n")
 }
 
 func TestRenderDependaBot(t *testing.T) {
@@ -217,3 +228,50 @@ func TestRenderDependaBotIntervals(t *testing.T) {
 		})
 	}
 }
+
+func TestRenderDependaBotOptions(t *testing.T) {
+	limit := 0
+	tmpl, err := RenderDependaBot(DependaBotResult{
+		Template: "dependabot-terraform.yml.tmpl",
+		Entries: []DependaBotEntry{{
+			Directory:             "infra",
+			Labels:                []string{"area: infra"},
+			Reviewers:             []string{"org/platform"},
+			Milestone:             3,
+			TargetBranch:          "develop",
+			OpenPullRequestsLimit: &limit,
+			BranchNameSeparator:   "/",
+			CommitMessagePrefix:   "tf",
+		}},
+	})
+	require.NoError(t, err)
+	assert.Contains(t, tmpl, "    open-pull-requests-limit: 0\n")
+	assert.Contains(t, tmpl, "    rebase-strategy: \"disabled\"\n")
+	assert.Contains(t, tmpl, "    commit-message:\n      prefix: \"tf\"\n      include: \"scope\"\n")
+	assert.Contains(t, tmpl, `    labels:
+      - "area: infra"
+    reviewers:
+      - "org/platform"
+    milestone: 3
+    target-branch: "develop"
+    pull-request-branch-name:
+      separator: "/"`)
+}
+
+func TestRenderDependaBotRules(t *testing.T) {
+	entry := DependaBotEntry{Directory: "infra", Interval: "weekly"}
+	tmpl, err := RenderDependaBot(DependaBotResult{Template: "dependabot-terraform.yml.tmpl", Entries: []DependaBotEntry{entry}})
+	require.NoError(t, err)
+	assert.NotContains(t, tmpl, "ignore:")
+
+	entry.Ignore = []Ignore{{DependencyName: "hashicorp/aws", Versions: []string{">= 6.0, < 7"}}}
+	entry.Allow = []Allow{{DependencyType: "direct"}}
+	tmpl, err = RenderDependaBot(DependaBotResult{Template: "dependabot-terraform.yml.tmpl", Entries: []DependaBotEntry{entry}})
+	require.NoError(t, err)
+	assert.Contains(t, tmpl, `    ignore:
+      - dependency-name: "hashicorp/aws"
+        versions:
+          [">= 6.0, < 7"]
+    allow:
+      - dependency-type: "direct"`)
+}